@@ -0,0 +1,238 @@
+package gotabulate
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Render the table as newline-delimited JSON, one object per row keyed by header
+// Empty and duplicate headers are made unique, nil and missing cells are written as null
+func (t *Tabulate) RenderNDJSON(w io.Writer) error {
+	headers, data := t.Headers, t.Data
+	// If headers are not set, use the first row
+	if len(headers) < 1 && len(data) > 0 {
+		headers, data = data[0].Elements, data[1:]
+	}
+
+	columns := len(headers)
+	for _, row := range data {
+		if len(row.Elements) > columns {
+			columns = len(row.Elements)
+		}
+	}
+	keys := uniqueHeaders(headers, columns)
+
+	for _, row := range data {
+		var buffer bytes.Buffer
+		buffer.WriteString("{")
+		for i, key := range keys {
+			if i > 0 {
+				buffer.WriteString(",")
+			}
+			k, _ := json.Marshal(key)
+			buffer.Write(k)
+			buffer.WriteString(":")
+			if len(row.Elements) <= i || row.Elements[i] == "nil" {
+				buffer.WriteString("null")
+			} else {
+				v, _ := json.Marshal(row.Elements[i])
+				buffer.Write(v)
+			}
+		}
+		buffer.WriteString("}\n")
+		if _, err := w.Write(buffer.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Render the data as fixed width records, every formatted cell justified into exactly widths[i] bytes
+// Longer cells are cut, without splitting a character, and there are no separators, so each field starts
+// at a known byte offset. Line breaks inside the cells are replaced by spaces so a row stays on one record.
+// Only the data is rendered, one line per row, and columns without a width are left out
+func (t *Tabulate) RenderFixed(widths []int) string {
+	if err := t.validate(); err != nil {
+		return ""
+	}
+	_, data := t.formattedRows()
+
+	// The fill char is only used when it is a single byte, to keep the offsets
+	fill := t.getFillChar()
+	if len(fill) != 1 {
+		fill = " "
+	}
+	var buffer bytes.Buffer
+	for _, row := range data {
+		for i, width := range widths {
+			cell := ""
+			if i < len(row.Elements) && row.Elements[i] != "nil" {
+				cell = fixedNewlines.Replace(row.Elements[i])
+			}
+			buffer.WriteString(padBytes(cutBytes(cell, width), width, t.getColumnAlign(i+t.indexOffset(), t.Align), fill))
+		}
+		buffer.WriteString("\n")
+	}
+	return buffer.String()
+}
+
+// Replaces the line breaks of the cells of fixed width records
+var fixedNewlines = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+// Cut a string to at most width bytes, without splitting a character
+func cutBytes(str string, width int) string {
+	if len(str) <= width {
+		return str
+	}
+	for width > 0 && !utf8.RuneStart(str[width]) {
+		width--
+	}
+	return str[:width]
+}
+
+// Pad a string to width bytes with a single byte fill, aligned like the cells of Render
+func padBytes(str string, width int, align string, fill string) string {
+	padding := width - len(str)
+	if padding < 1 {
+		return str
+	}
+	switch align {
+	case "left":
+		return str + strings.Repeat(fill, padding)
+	case "center":
+		left := (padding + 1) / 2
+		return strings.Repeat(fill, left) + str + strings.Repeat(fill, padding-left)
+	default:
+		return strings.Repeat(fill, padding) + str
+	}
+}
+
+// Escapes the content of cells for Graphviz HTML-like labels
+var graphvizEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\"", "&quot;", "\n", "<BR/>")
+
+// Render the table as a Graphviz HTML-like label, to embed in a DOT file as <...>
+// Every cell is a <TD> aligned like its column, the headers are bold
+func (t *Tabulate) RenderGraphvizLabel() string {
+	headers, data := t.Headers, t.Data
+	// If headers are not set, use the first row
+	if len(headers) < 1 && len(data) > 0 {
+		headers, data = data[0].Elements, data[1:]
+	}
+
+	var buffer bytes.Buffer
+	writeRow := func(elements []string, align string, header bool) {
+		buffer.WriteString("<TR>")
+		for i, element := range elements {
+			if element == "nil" {
+				element = ""
+			}
+			element = graphvizEscaper.Replace(element)
+			if header {
+				element = "<B>" + element + "</B>"
+			}
+			buffer.WriteString(`<TD ALIGN="` + graphvizAlign(t.getColumnAlign(i+t.indexOffset(), align)) + `">` + element + "</TD>")
+		}
+		buffer.WriteString("</TR>\n")
+	}
+
+	buffer.WriteString(`<TABLE BORDER="0" CELLBORDER="1">` + "\n")
+	writeRow(headers, t.getHeaderAlign(), true)
+	for _, row := range data {
+		writeRow(row.Elements, t.Align, false)
+	}
+	buffer.WriteString("</TABLE>")
+	return buffer.String()
+}
+
+// Get the ALIGN attribute of a Graphviz cell for an align type
+func graphvizAlign(align string) string {
+	switch align {
+	case "left":
+		return "LEFT"
+	case "center":
+		return "CENTER"
+	default:
+		return "RIGHT"
+	}
+}
+
+// Render left-aligned columns separated by at least minGap spaces, like `column -t`
+// There are no borders or lines and the last column is not padded. Empty cells use the empty string of their column
+func (t *Tabulate) RenderColumnar(w io.Writer, minGap int) error {
+	_, data := t.prepare()
+
+	rows := make([]*TabulateRow, len(data))
+	for i, row := range data {
+		elements := make([]string, len(t.headers))
+		for j := range elements {
+			if j < len(row.Elements) && row.Elements[j] != "nil" {
+				elements[j] = row.Elements[j]
+			} else {
+				elements[j] = t.getEmptyString(j)
+			}
+		}
+		rows[i] = &TabulateRow{Elements: elements}
+	}
+	widths := t.getWidths(t.headers, rows)
+
+	gap := createBuffer().Write(" ", minGap).String()
+	writeRow := func(elements []string) error {
+		var buffer bytes.Buffer
+		for i, element := range elements {
+			if i == len(elements)-1 {
+				buffer.WriteString(strings.TrimRight(element, " "))
+				break
+			}
+			buffer.WriteString(t.padRight(widths[i], element, t.getFillChar()))
+			buffer.WriteString(gap)
+		}
+		buffer.WriteString("\n")
+		_, err := w.Write(buffer.Bytes())
+		return err
+	}
+
+	if err := writeRow(t.headers); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := writeRow(row.Elements); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Make headers usable as unique keys for count columns
+// Headers are padded on the left like in Render, empty headers are named after
+// their column ("column_1") and duplicates get a numeric suffix ("name_2")
+func uniqueHeaders(headers []string, count int) []string {
+	keys := make([]string, count)
+	offset := count - len(headers)
+	for i := range keys {
+		if i >= offset {
+			keys[i] = headers[i-offset]
+		}
+		if len(keys[i]) < 1 {
+			keys[i] = "column_" + strconv.Itoa(i+1)
+		}
+	}
+
+	seen := make(map[string]int)
+	for i, key := range keys {
+		seen[key]++
+		for seen[key] > 1 {
+			candidate := key + "_" + strconv.Itoa(seen[key])
+			if seen[candidate] == 0 {
+				keys[i] = candidate
+				seen[candidate]++
+				break
+			}
+			seen[key]++
+		}
+	}
+	return keys
+}
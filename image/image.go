@@ -0,0 +1,98 @@
+// Package image renders gotabulate tables as PNG images.
+// It is separate from gotabulate so only the programs rendering images depend on the font rasterizer
+package image
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+
+	"github.com/bndr/gotabulate"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Options of the rendered image
+type Options struct {
+	// Format the table is rendered with, the format defined in the struct if empty
+	Format string
+	// TTF font data, the bundled Go Mono font if empty
+	Font []byte
+	// Font size in points, 14 if zero
+	FontSize float64
+	// Text and background colors, black on white if nil
+	Foreground color.Color
+	Background color.Color
+	// Padding around the table in pixels
+	Padding int
+}
+
+// Render the table as a PNG image
+// Every line of the rendered table is drawn on a monospace grid, so wide runes keep their alignment.
+// The ANSI escape sequences of the colors are left out, the image is drawn in the foreground color
+func Render(w io.Writer, t *gotabulate.Tabulate, opts Options) error {
+	var rendered string
+	if len(opts.Format) > 0 {
+		rendered = t.Render(opts.Format)
+	} else {
+		rendered = t.Render()
+	}
+	lines := strings.Split(strings.TrimRight(gotabulate.StripANSI(rendered), "\n"), "\n")
+
+	if len(opts.Font) < 1 {
+		opts.Font = gomono.TTF
+	}
+	if opts.FontSize <= 0 {
+		opts.FontSize = 14
+	}
+	if opts.Foreground == nil {
+		opts.Foreground = color.Black
+	}
+	if opts.Background == nil {
+		opts.Background = color.White
+	}
+
+	parsed, err := opentype.Parse(opts.Font)
+	if err != nil {
+		return err
+	}
+	face, err := opentype.NewFace(parsed, &opentype.FaceOptions{Size: opts.FontSize, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return err
+	}
+	defer face.Close()
+
+	// Size of a cell of the monospace grid
+	advance, _ := face.GlyphAdvance('M')
+	cellWidth := advance.Ceil()
+	metrics := face.Metrics()
+	lineHeight := metrics.Height.Ceil()
+
+	columns := 0
+	for _, line := range lines {
+		if width := runewidth.StringWidth(line); width > columns {
+			columns = width
+		}
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, columns*cellWidth+2*opts.Padding, len(lines)*lineHeight+2*opts.Padding))
+	draw.Draw(img, img.Bounds(), image.NewUniform(opts.Background), image.Point{}, draw.Src)
+
+	drawer := &font.Drawer{Dst: img, Src: image.NewUniform(opts.Foreground), Face: face}
+	for i, line := range lines {
+		y := opts.Padding + i*lineHeight + metrics.Ascent.Ceil()
+		column := 0
+		for _, r := range line {
+			drawer.Dot = fixed.P(opts.Padding+column*cellWidth, y)
+			drawer.DrawString(string(r))
+			column += runewidth.RuneWidth(r)
+		}
+	}
+	return png.Encode(w, img)
+}
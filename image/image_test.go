@@ -0,0 +1,32 @@
+package image
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/bndr/gotabulate"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	tabulate := gotabulate.Create([][]string{{"Lorem", "ipsum", "dolor", "sit"}})
+	tabulate.SetHeaders([]string{"Header 1", "Header 2", "Header 3", "Header 4"})
+	var buffer bytes.Buffer
+	assert.Nil(t, Render(&buffer, tabulate, Options{Format: "grid", Padding: 4}))
+
+	img, err := png.Decode(&buffer)
+	assert.Nil(t, err)
+	// 5 lines of 57 columns on a monospace grid
+	assert.Equal(t, 0, (img.Bounds().Dx()-8)%57)
+	assert.Equal(t, 0, (img.Bounds().Dy()-8)%5)
+	assert.True(t, img.Bounds().Dy() > 5*14)
+
+	// The colors are not drawn, nor counted in the width
+	tabulate.SetDataColor("\033[31m", "\033[0m")
+	var colored bytes.Buffer
+	assert.Nil(t, Render(&colored, tabulate, Options{Format: "grid", Padding: 4}))
+	coloredImg, err := png.Decode(&colored)
+	assert.Nil(t, err)
+	assert.Equal(t, img, coloredImg)
+}
@@ -0,0 +1,43 @@
+package gotabulate
+
+import (
+	"database/sql"
+)
+
+// Create a new Tabulate Object from the result of a query
+// The names of the columns are the headers, and every row is scanned into the mixed values path.
+// Byte slices are converted to strings. The rows are not closed, which is left to the caller
+func CreateFromRows(rows *sql.Rows) (*Tabulate, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var data [][]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		for i, value := range values {
+			switch v := value.(type) {
+			case []byte:
+				values[i] = string(v)
+			case sql.RawBytes:
+				values[i] = string(v)
+			}
+		}
+		data = append(data, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	t := Create(data)
+	t.SetHeaders(columns)
+	return t, nil
+}
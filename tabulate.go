@@ -2,9 +2,17 @@ package gotabulate
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"math"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/mattn/go-runewidth"
 	"github.com/nsf/termbox-go"
@@ -21,6 +29,7 @@ type TableFormat struct {
 	Padding         int
 	HeaderHide      bool
 	FitScreen       bool
+	HideLines       []string
 }
 
 // Represents a Line
@@ -39,8 +48,10 @@ type Row struct {
 }
 
 // Table Formats that are available to the user
-// The user can define his own format, just by addind an entry to this map
+// The user can define his own format, by registering it with RegisterFormat
 // and calling it with Render function e.g t.Render("customFormat")
+// Accessing the map directly is not synchronized: use RegisterFormat, UnregisterFormat
+// and GetFormat when other goroutines may render at the same time
 var TableFormats = map[string]TableFormat{
 	"simple": TableFormat{
 		LineTop:         Line{"", "-", "  ", ""},
@@ -55,6 +66,13 @@ var TableFormats = map[string]TableFormat{
 		DataRow:   Row{"", "  ", ""},
 		Padding:   1,
 	},
+	"headeronly": TableFormat{
+		LineBelowHeader: Line{"", "-", "  ", ""},
+		HeaderRow:       Row{"", "  ", ""},
+		DataRow:         Row{"", "  ", ""},
+		Padding:         1,
+		HideLines:       []string{"top", "betweenrows", "bottomLine"},
+	},
 	"grid": TableFormat{
 		LineTop:         Line{"+", "-", "+", "+"},
 		LineBelowHeader: Line{"+", "=", "+", "+"},
@@ -64,6 +82,15 @@ var TableFormats = map[string]TableFormat{
 		DataRow:         Row{"|", "|", "|"},
 		Padding:         1,
 	},
+	"ascii_grid": TableFormat{
+		LineTop:         Line{"+", "-", "+", "+"},
+		LineBelowHeader: Line{"+", "-", "+", "+"},
+		LineBetweenRows: Line{"+", "-", "+", "+"},
+		LineBottom:      Line{"+", "-", "+", "+"},
+		HeaderRow:       Row{"|", "|", "|"},
+		DataRow:         Row{"|", "|", "|"},
+		Padding:         1,
+	},
 	"border": TableFormat{
 		LineTop:         Line{"┏", "━", "┳", "┓"},
 		LineBelowHeader: Line{"┡", "━", "╇", "┩"},
@@ -75,27 +102,163 @@ var TableFormats = map[string]TableFormat{
 	},
 }
 
-// Minimum padding that will be applied
+// Guards TableFormats
+var formatsMutex sync.RWMutex
+
+// Serializes the terminal size requests, termbox is global
+var termboxMutex sync.Mutex
+
+// Background colors of the heatmap, from cool to warm in the 256 colors palette
+var HEATMAP_COLORS = []int{21, 33, 45, 51, 48, 46, 118, 226, 214, 208, 196}
+
+// Minimum padding that will be applied, autosized tables use a padding of 2
 var MIN_PADDING = 5
 
 // Main Tabulate structure
 type Tabulate struct {
-	Data        []*TabulateRow
-	Headers     []string
-	FloatFormat byte
-	TableFormat TableFormat
-	Align       string
-	EmptyVar    string
-	HideLines   []string
-	MaxSize     int
-	WrapStrings bool
-	AutoSize    bool
+	Data                  []*TabulateRow
+	Headers               []string
+	FloatFormat           byte
+	TrimTrailingZeros     bool
+	DateFormat            string
+	TableFormat           TableFormat
+	Align                 string
+	HeaderAlign           string
+	EmptyVar              string
+	ColumnEmptyStrings    map[int]string
+	HideLines             []string
+	MaxSize               int
+	WrapStrings           bool
+	TruncateInsteadOfWrap bool
+	AutoSize              bool
+	AutoSizeShrinkHeaders int
+	ASCIIFast             bool
+	NaNFormat             string
+	InfFormat             string
+	TrueFormat            string
+	FalseFormat           string
+	DecimalMark           rune
+	GroupingMark          rune
+	ContinuationMarker    string
+	WrapIndent            int
+	WrapColumns           []int
+	VerbatimColumns       []int
+	SparseColumns         []int
+	ColumnWidthPercents   map[int]float64
+	ColumnWidthRanges     map[int][2]int
+	MaxTableWidth         int
+	CellMaxLines          int
+	CJKWrap               bool
+	HyphenateWrap         bool
+	ColumnTruncateSides   map[int]string
+	ColumnPadding         []int
+	RowVerticalPadding    [2]int
+	HeaderVerticalPadding [2]int
+	ColumnSeparators      []string
+	IndexColumn           string
+	AutoFooter            map[int]string
+	GrandTotalLabel       string
+	GrandTotalColumns     []int
+	NegativeColorPrefix   string
+	NegativeColorSuffix   string
+	DataColorPrefix       string
+	DataColorSuffix       string
+	ColumnZebraColors     [2][2]string
+	ColumnZebraHeaders    bool
+	HeatmapColumns        []int
+	Previous              [][]string
+	ChangePrefix          string
+	ChangeSuffix          string
+	RowColorFunc          func(cells []string) (prefix, suffix string, ok bool)
+	FillChar              rune
+	PadFillFunc           func(row, col int, content string) rune
+	ColumnTypes           []ColumnType
+	AlignByRegex          map[int]RegexAlign
+	AlignChars            map[int]rune
+	CurrencySymbols       map[int]string
+	ColumnFormatters      map[int]func(string) string
+	ColumnComparators     map[int]func(a, b string) bool
+	ColumnPrefixes        map[int]string
+	ColumnSuffixes        map[int]string
+	RaggedFill            string
+	TabWidth              int
+	HeaderTransform       func(string) string
+	HeaderIcons           map[int]string
+	ShowSortIndicators    bool
+	HeaderCommentPrefix   string
+	MergeEqualHeaders     bool
+	VerticalHeaders       bool
+	ReverseColumns        bool
+	StripZeroWidth        bool
+	EscapeControl         bool
+	MaxColumns            int
+	HideEmptyColumns      bool
+	StrictColumns         bool
+	ColumnWidthMode       string
+	HardWrapWidth         int
+	LineHook              func(lineIndex int, line string)
+	RenderTimeout         time.Duration
+	SeparatorEvery        int
+	HeaderSeparatorChar   rune
+	RowSeparatorChar      rune
+	Notes                 []Note
+	ShowRowCount          bool
+	RowCountFormat        string
+	footer                []string
+	headers               []string
+	headerRows            []*TabulateRow
+	notes                 []string
+	total                 []string
+	sortKeys              []SortKey
+	percentWidths         map[int]int
+	hidden                map[int]bool
+	heatmap               map[int][2]float64
+	escapedSeparator      string
+	minPadding            int
+}
+
+// Type of the values of a column
+type ColumnType int
+
+const (
+	ColumnText ColumnType = iota
+	ColumnInteger
+	ColumnFloat
+	ColumnBool
+	ColumnDate
+	ColumnCurrency
+)
+
+// Align of the cells of a column, depending on their content matching a pattern
+type RegexAlign struct {
+	Pattern *regexp.Regexp
+	Match   string
+	NoMatch string
+}
+
+// Column to sort the rows by, in ascending order unless Descending is set
+type SortKey struct {
+	Column     int
+	Descending bool
+}
+
+// Footnote of a cell, listed below the table
+type Note struct {
+	Row    int
+	Column int
+	Text   string
 }
 
 // Represents normalized tabulate Row
+// Separator forces a line after the row, to group rows into sections
 type TabulateRow struct {
 	Elements   []string
 	Continuous bool
+	Separator  bool
+	values     []interface{}
+	index      int
+	changed    []bool
+	color      [2]string
 }
 
 type writeBuffer struct {
@@ -117,100 +280,492 @@ func (b *writeBuffer) String() string {
 }
 
 // Add padding to each cell
-func (t *Tabulate) padRow(arr []string, padding int) []string {
+func (t *Tabulate) padRow(arr []string) []string {
 	if len(arr) < 1 {
 		return arr
 	}
 	padded := make([]string, len(arr))
 	for index, el := range arr {
-		b := createBuffer()
-		b.Write(" ", padding)
-		b.Write(el, 1)
-		b.Write(" ", padding)
-		padded[index] = b.String()
+		padded[index] = t.padCell(index, el)
 	}
 	return padded
 }
 
+// Pad a cell with the padding of its column on both sides
+func (t *Tabulate) padCell(column int, el string) string {
+	b := createBuffer()
+	b.Write(" ", t.getPadding(column))
+	b.Write(el, 1)
+	b.Write(" ", t.getPadding(column))
+	return b.String()
+}
+
+// Get the character the cells are filled with when aligned, a space by default
+func (t *Tabulate) getFillChar() string {
+	if t.FillChar == 0 {
+		return " "
+	}
+	return string(t.FillChar)
+}
+
 // Align right (Add padding left)
-func (t *Tabulate) padLeft(width int, str string) string {
+func (t *Tabulate) padLeft(width int, str string, fill string) string {
 	b := createBuffer()
-	b.Write(" ", (width - runewidth.StringWidth(str)))
+	b.Write(fill, (width - t.stringWidth(str)))
 	b.Write(str, 1)
 	return b.String()
 }
 
 // Align Left (Add padding right)
-func (t *Tabulate) padRight(width int, str string) string {
+func (t *Tabulate) padRight(width int, str string, fill string) string {
 	b := createBuffer()
 	b.Write(str, 1)
-	b.Write(" ", (width - runewidth.StringWidth(str)))
+	b.Write(fill, (width - t.stringWidth(str)))
 	return b.String()
 }
 
 // Center the element in the cell
-func (t *Tabulate) padCenter(width int, str string) string {
+func (t *Tabulate) padCenter(width int, str string, fill string) string {
 	b := createBuffer()
-	padding := int(math.Ceil(float64((width - runewidth.StringWidth(str))) / 2.0))
-	b.Write(" ", padding)
+	padding := int(math.Ceil(float64((width - t.stringWidth(str))) / 2.0))
+	b.Write(fill, padding)
 	b.Write(str, 1)
-	b.Write(" ", (width - runewidth.StringWidth(b.String())))
+	b.Write(fill, (width - t.stringWidth(b.String())))
 
 	return b.String()
 }
 
 // Build Line based on padded_widths from t.GetWidths()
+// The junctions of the line are fitted to the separators of the data row, so both always align
 func (t *Tabulate) buildLine(padded_widths []int, padding []int, l Line) string {
+	// A line without any glyphs renders as an empty line
+	if l == (Line{}) {
+		return ""
+	}
+
+	// Without a horizontal line the junctions are still aligned with spaces
+	fill := l.hline
+
 	cells := make([]string, len(padded_widths))
 
 	for i, _ := range cells {
-		b := createBuffer()
-		b.Write(l.hline, padding[i]+MIN_PADDING)
-		cells[i] = b.String()
+		cells[i] = repeatFill(fill, padded_widths[i])
 	}
 
+	d := t.TableFormat.DataRow
 	var buffer bytes.Buffer
-	buffer.WriteString(l.begin)
+	buffer.WriteString(fitGlyph(l.begin, fill, runewidth.StringWidth(d.begin)))
 
 	// Print contents
-	for i := 0; i < len(cells); i++ {
+	order := t.columnOrder(len(cells))
+	for k, i := range order {
 		buffer.WriteString(cells[i])
-		if i != len(cells)-1 {
-			buffer.WriteString(l.sep)
+		if k != len(order)-1 {
+			buffer.WriteString(fitGlyph(l.sep, fill, runewidth.StringWidth(t.getSeparator(columnGap(order, k), d))))
 		}
 	}
 
-	buffer.WriteString(l.end)
+	buffer.WriteString(fitGlyph(l.end, fill, runewidth.StringWidth(d.end)))
 	return buffer.String()
 }
 
 // Build Row based on padded_widths from t.GetWidths()
-func (t *Tabulate) buildRow(elements []string, padded_widths []int, paddings []int, d Row) string {
+// The row is nil for the header and footer
+// The logical row is the number of the data row from 0, given to PadFillFunc
+func (t *Tabulate) buildRow(elements []string, padded_widths []int, paddings []int, d Row, row *TabulateRow, logical int) string {
+	header := row == nil
 
 	var buffer bytes.Buffer
 	buffer.WriteString(d.begin)
-	padFunc := t.getAlignFunc()
+	align := t.Align
+	if header {
+		align = t.getHeaderAlign()
+	}
+	// Short rows are missing their first columns when filled on the left
+	missing := 0
+	if t.RaggedFill == "left" && len(elements) < len(padded_widths) {
+		missing = len(padded_widths) - len(elements)
+	}
 	// Print contents
-	for i := 0; i < len(padded_widths); i++ {
+	order := t.columnOrder(len(padded_widths))
+	for k, i := range order {
+		cellAlign := t.getColumnAlign(i, align)
+		// index of the element shown in the column, past the elements for a missing cell
+		e := i
+		if i >= t.indexOffset() {
+			if e -= missing; e < t.indexOffset() {
+				e = len(elements)
+			}
+		}
+		// Align the cell by its content if the column has a regex
+		if !header && len(elements) > e {
+			if rule, ok := t.AlignByRegex[e-t.indexOffset()]; ok {
+				if rule.Pattern.MatchString(strings.TrimSpace(elements[e])) {
+					cellAlign = rule.Match
+				} else {
+					cellAlign = rule.NoMatch
+				}
+			}
+		}
+		// Fill the data cell with the character chosen for it
+		fill := t.getFillChar()
+		if !header && t.PadFillFunc != nil && e >= t.indexOffset() {
+			content := ""
+			if len(elements) > e {
+				content = strings.TrimSpace(elements[e])
+			}
+			if r := t.PadFillFunc(logical, e-t.indexOffset(), content); runewidth.RuneWidth(r) == 1 {
+				fill = string(r)
+			}
+		}
+		padFunc := t.getAlignFunc(cellAlign, fill)
 		output := ""
-		if len(elements) <= i || (len(elements) > i && elements[i] == " nil ") {
-			output = padFunc(padded_widths[i], t.EmptyVar)
-		} else if len(elements) > i {
-			output = padFunc(padded_widths[i], elements[i])
+		if len(elements) <= e || (len(elements) > e && elements[e] == t.padCell(e, "nil")) {
+			// the empty string is padded like the cells, so it is aligned the same way
+			output = padFunc(padded_widths[i], t.padCell(i, t.getEmptyString(e-t.indexOffset())))
+		} else if len(elements) > e {
+			output = padFunc(padded_widths[i], elements[e])
+		}
+		uncolored := output
+		// Color negative numbers, the codes wrap the padded cell so the width is unaffected
+		if !header && len(elements) > e && len(t.NegativeColorPrefix) > 0 && t.isNegative(elements[e]) {
+			output = t.NegativeColorPrefix + output + t.NegativeColorSuffix
+		}
+		// Shade the cells of the heatmap columns, the codes wrap the padded cell too
+		if !header && len(elements) > e {
+			output = t.shadeCell(e-t.indexOffset(), elements[e], output)
+		}
+		// Shade every other column, counting the displayed data columns
+		if (!header || t.ColumnZebraHeaders) && output == uncolored && i >= t.indexOffset() {
+			if zebra := t.ColumnZebraColors[(k-t.indexOffset())%2]; len(zebra[0]) > 0 {
+				output = zebra[0] + output + zebra[1]
+			}
+		}
+		// Color the data cells that have no color of their own
+		if !header && output == uncolored && e >= t.indexOffset() && len(t.DataColorPrefix) > 0 {
+			output = t.DataColorPrefix + output + t.DataColorSuffix
+		}
+		// Highlight the cells that changed since the previous data
+		if !header && e-t.indexOffset() >= 0 && e-t.indexOffset() < len(row.changed) && row.changed[e-t.indexOffset()] {
+			output = t.getChangePrefix() + output + t.getChangeSuffix()
 		}
 		buffer.WriteString(output)
-		if i != len(padded_widths)-1 {
-			buffer.WriteString(d.sep)
+		if k != len(order)-1 {
+			buffer.WriteString(t.getSeparator(columnGap(order, k), d))
 		}
 	}
 
 	buffer.WriteString(d.end)
+	// Color the whole row, separators included
+	if !header && len(row.color[0]) > 0 {
+		return row.color[0] + buffer.String() + row.color[1]
+	}
 	return buffer.String()
 }
 
 // Render the data table
 func (t *Tabulate) Render(format ...interface{}) string {
+	cols, data := t.prepare(format...)
+	return t.output(t.buildTable(data, cols))
+}
+
+// Render the data table as lines, without line breaks
+func (t *Tabulate) RenderLines(format ...interface{}) []string {
+	return strings.Split(strings.TrimSuffix(t.Render(format...), "\n"), "\n")
+}
+
+// Render tables side by side in a grid, each with its own format
+// The tables of a grid column are aligned, and gap spaces or empty lines separate the tables.
+// A nil table leaves its cell of the grid empty
+func RenderGrid(tables [][]*Tabulate, gap int) string {
+	// render every table, and get the width of each grid column
+	blocks := make([][][]string, len(tables))
+	var widths []int
+	for i, row := range tables {
+		blocks[i] = make([][]string, len(row))
+		for j, t := range row {
+			if j >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if t == nil {
+				continue
+			}
+			blocks[i][j] = t.RenderLines()
+			for _, line := range blocks[i][j] {
+				if w := visibleWidth(line); w > widths[j] {
+					widths[j] = w
+				}
+			}
+		}
+	}
+
+	var lines []string
+	for i, row := range blocks {
+		if i > 0 {
+			for k := 0; k < gap; k++ {
+				lines = append(lines, "")
+			}
+		}
+		height := 0
+		for _, block := range row {
+			if len(block) > height {
+				height = len(block)
+			}
+		}
+		// shorter tables are padded with empty lines
+		for k := 0; k < height; k++ {
+			var b bytes.Buffer
+			// columns written so far, and column where the next table starts
+			// the padding is only written before a table line, never after the last one
+			column, start := 0, 0
+			for j, block := range row {
+				if k < len(block) && len(block[k]) > 0 {
+					b.WriteString(strings.Repeat(" ", start-column))
+					b.WriteString(block[k])
+					column = start + visibleWidth(block[k])
+				}
+				start += widths[j] + gap
+			}
+			lines = append(lines, b.String())
+		}
+	}
+	return joinLines(lines)
+}
+
+// Render the header and a window of height logical rows, starting at offset
+// Wrapped continuation rows are part of their logical row.
+// Returns the rendered table and the total number of logical rows
+func (t *Tabulate) RenderWindow(offset, height int, format string) (output string, total int) {
+	cols, data := t.prepare(format)
+	rows := logicalRows(data)
+
+	start := offset
+	if start < 0 {
+		start = 0
+	} else if start > len(rows) {
+		start = len(rows)
+	}
+	end := start + height
+	if end > len(rows) || height < 0 {
+		end = len(rows)
+	}
+
+	var window []*TabulateRow
+	for _, row := range rows[start:end] {
+		window = append(window, row...)
+	}
+	return t.output(t.buildTable(window, cols)), len(rows)
+}
+
+// Render the table in pages of rowsPerPage logical rows, separated by form feeds
+// Every page repeats the header and has its own bottom line. Wrapped rows are never split across pages
+func (t *Tabulate) RenderPaged(rowsPerPage int, format string) string {
+	cols, data := t.prepare(format)
+	rows := logicalRows(data)
+	if rowsPerPage < 1 {
+		rowsPerPage = len(rows)
+	}
+
+	var pages []string
+	for start := 0; start < len(rows); start += rowsPerPage {
+		end := start + rowsPerPage
+		if end > len(rows) {
+			end = len(rows)
+		}
+		var page []*TabulateRow
+		for _, row := range rows[start:end] {
+			page = append(page, row...)
+		}
+		pages = append(pages, t.output(t.buildTable(page, cols)))
+	}
+	return strings.Join(pages, "\f")
+}
+
+// Render tables stacked on each other, sharing the same column widths
+// A single line between rows joins adjacent tables, instead of a bottom and a top line
+func RenderStacked(tables []*Tabulate, format string) string {
+	// prepare every table, and widen the columns to fit all of them
+	var widths []int
+	data := make([][]*TabulateRow, len(tables))
+	for i, t := range tables {
+		var cols []int
+		cols, data[i] = t.prepare(format)
+		for j, w := range cols {
+			if j >= len(widths) {
+				widths = append(widths, w)
+			} else if w > widths[j] {
+				widths[j] = w
+			}
+		}
+	}
+
 	var lines []string
+	for i, t := range tables {
+		cols := make([]int, len(widths))
+		copy(cols, widths)
+		table := t.buildTable(data[i], cols)
+		if i > 0 {
+			if !t.hidesLine("top") {
+				table = table[1:]
+			}
+			lines = append(lines, t.buildLine(t.getPaddedWidths(cols), cols, t.getLineAfter(&TabulateRow{Separator: true})))
+		}
+		if i < len(tables)-1 && !t.hidesLine("bottomLine") {
+			bottom := len(table) - len(t.notes) - 1
+			if t.ShowRowCount {
+				bottom--
+			}
+			table = append(table[:bottom], table[bottom+1:]...)
+		}
+		lines = append(lines, table...)
+	}
+	return joinLines(lines)
+}
+
+// Render the data table without whitespace at the end of the lines
+// The alignment inside the lines is kept, which makes the output safe to embed in YAML or JSON strings
+func (t *Tabulate) RenderTrimmed(format string) string {
+	cols, data := t.prepare(format)
+	lines := t.buildTable(data, cols)
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return t.output(lines)
+}
+
+// Render the data table, returning an error instead of panicking if the table cannot be rendered
+func (t *Tabulate) SafeRender(format ...interface{}) (string, error) {
+	if err := t.validate(); err != nil {
+		return "", err
+	}
+	if len(format) > 0 {
+		if _, err := GetFormat(format[0].(string)); err != nil {
+			return "", err
+		}
+	}
+	return t.Render(format...), nil
+}
+
+// Render the data table, returning an error if ctx is done or RenderTimeout is exceeded before it is rendered
+// The headers and the data of the table are left as they were when the rendering is canceled
+func (t *Tabulate) RenderContext(ctx context.Context, format string) (string, error) {
+	if t.RenderTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.RenderTimeout)
+		defer cancel()
+	}
+	cols, data, err := t.prepareContext(ctx, format)
+	if err != nil {
+		return "", err
+	}
+	lines, err := t.buildTableContext(ctx, data, cols)
+	if err != nil {
+		return "", err
+	}
+	return t.output(lines), nil
+}
+
+// Get the error of a canceled rendering, nil while ctx is not done
+func renderCanceled(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("render canceled: %w", err)
+	}
+	return nil
+}
+
+// Set the maximum duration of RenderContext
+// Reading the terminal width is not interrupted by the timeout, set MaxTableWidth for autosized tables
+func (t *Tabulate) SetRenderTimeout(d time.Duration) {
+	t.RenderTimeout = d
+}
+
+// Register a format, to render tables with it by name
+// Registering is safe while other goroutines render through Render or GetFormat,
+// and names that already exist are rejected
+func RegisterFormat(name string, f TableFormat) error {
+	formatsMutex.Lock()
+	defer formatsMutex.Unlock()
+	if _, ok := TableFormats[name]; ok {
+		return fmt.Errorf("format %q already exists", name)
+	}
+	TableFormats[name] = f
+	return nil
+}
+
+// Remove a registered format
+func UnregisterFormat(name string) {
+	formatsMutex.Lock()
+	defer formatsMutex.Unlock()
+	delete(TableFormats, name)
+}
+
+// Get a format by name, with an error listing the available formats if it does not exist
+func GetFormat(name string) (TableFormat, error) {
+	formatsMutex.RLock()
+	defer formatsMutex.RUnlock()
+	format, ok := TableFormats[name]
+	if !ok {
+		var names []string
+		for n := range TableFormats {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return format, fmt.Errorf("unknown format %q, available: %v", name, names)
+	}
+	return format, nil
+}
+
+// Check that there is data with at least one column to render
+func (t *Tabulate) validate() error {
+	headers, data := t.Headers, t.Data
+	// The first row will be used as header if headers are not set
+	if len(headers) < 1 && len(data) > 0 {
+		headers, data = data[0].Elements, data[1:]
+	}
+
+	// Check if Data is present
+	if len(data) < 1 {
+		return errors.New("No Data specified")
+	}
+
+	// Columns are given by the headers and the first row
+	if len(headers) < 1 && len(data[0].Elements) < 1 {
+		return errors.New("No columns specified")
+	}
+
+	// Every row must have a cell for each header with strict columns
+	if t.StrictColumns {
+		for i, row := range data {
+			if len(row.Elements) != len(headers) {
+				return fmt.Errorf("row %d has %d columns, expected %d", i, len(row.Elements), len(headers))
+			}
+		}
+	}
+	return nil
+}
+
+// Prepare headers and data for rendering and calculate the column widths
+// Returns the column widths and the data rows to render, the data of the table itself is not wrapped
+func (t *Tabulate) prepare(format ...interface{}) ([]int, []*TabulateRow) {
+	cols, data, err := t.prepareContext(context.Background(), format...)
+	if err != nil {
+		panic(err.Error())
+	}
+	return cols, data
+}
+
+// Prepare headers and data for rendering, returning an error if they are invalid or ctx is done before they are prepared
+// The headers and the data of the table are left as they were on errors
+func (t *Tabulate) prepareContext(ctx context.Context, format ...interface{}) (cols []int, data []*TabulateRow, err error) {
+	if err := t.validate(); err != nil {
+		return nil, nil, err
+	}
+	keptHeaders, keptData := t.Headers, t.Data
+	defer func() {
+		if err != nil {
+			t.Headers, t.Data = keptHeaders, keptData
+		}
+	}()
 
 	// If headers are set use them, otherwise pop the first row
 	if len(t.Headers) < 1 {
@@ -220,12 +775,11 @@ func (t *Tabulate) Render(format ...interface{}) string {
 	// Use the format that was passed as parameter, otherwise
 	// use the format defined in the struct
 	if len(format) > 0 {
-		t.TableFormat = TableFormats[format[0].(string)]
-	}
-
-	// Check if Data is present
-	if len(t.Data) < 1 {
-		panic("No Data specified")
+		tableFormat, err := GetFormat(format[0].(string))
+		if err != nil {
+			return nil, nil, err
+		}
+		t.TableFormat = tableFormat
 	}
 
 	if len(t.Headers) < len(t.Data[0].Elements) {
@@ -237,103 +791,616 @@ func (t *Tabulate) Render(format ...interface{}) string {
 		t.Headers = padded_header
 	}
 
-	var cols []int
+	// Transform the headers once, before the widths are computed
+	t.headers = t.escapeHeaders(t.transformHeaders(t.Headers))
+
+	// Compute the footer before the cells are wrapped
+	t.footer = t.getFooter()
+	t.total = t.getGrandTotal()
+
+	// Format and clean the cells, on copies of the rows
+	data = t.formatCells(t.Data)
+	t.notes = t.getNotes(data)
+	t.headers, t.footer, t.total = t.limitHeaders(t.headers), t.limitFooter(t.footer), t.limitFooter(t.total)
+	if err := renderCanceled(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	// Get the range of the heatmap columns before the cells are wrapped
+	t.heatmap = t.getHeatmapRanges(data)
+
+	// The headers are the minimum width of the columns, unless the width is driven by the content
+	measured := t.headers
+	if t.ColumnWidthMode == "content" {
+		measured = make([]string, len(t.headers))
+	}
+
+	// Vertical headers are one character wide
+	var vertical []*TabulateRow
+	if t.VerticalHeaders {
+		vertical, measured = t.verticalHeaders()
+	}
+
+	// Find the empty columns to hide, their headers are not measured
+	t.hidden = t.getEmptyColumns(data)
+	if len(t.hidden) > 0 {
+		measured = append([]string(nil), measured...)
+		for i := range t.hidden {
+			measured[i] = ""
+		}
+	}
+
+	// The headers are rendered on several lines when they are wrapped
+	t.headerRows = []*TabulateRow{{Elements: t.headers}}
+	wrapHeaders := t.ColumnWidthMode != "content"
+	if t.VerticalHeaders {
+		t.headerRows = vertical
+		wrapHeaders = false
+	}
+
+	// Get the width available to the table once, if the columns are sized from it
+	width := 0
+	if t.AutoSize || len(t.ColumnWidthPercents) > 0 {
+		if width, err = t.tableWidth(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Resolve the width of the columns given as a percentage of the table width
+	t.percentWidths = t.resolvePercentWidths(len(t.headers), width)
+
 	if t.AutoSize {
 		// get max size for each column
-		cols = t.getWidths(t.Headers, t.Data)
+		cols = t.getWidths(measured, data)
 		// if autosize, calculate new column sizes and wrap data with the result
-		cols = t.autoSize(t.Headers, cols)
+		cols = t.autoSize(measured, cols, width)
+		for i, w := range t.percentWidths {
+			cols[i] = w
+		}
+		cols = t.clampWidths(cols)
+		// Cut the headers of the columns shrunk below their width
+		if t.AutoSizeShrinkHeaders > 0 && !t.VerticalHeaders {
+			headers := make([]string, len(t.headers))
+			for i, header := range t.headers {
+				headers[i] = header
+				if i < len(cols) && t.stringWidth(header) > cols[i] {
+					headers[i] = t.truncate(header, cols[i]-1) + "…"
+				}
+			}
+			t.headers = headers
+			t.headerRows = []*TabulateRow{{Elements: headers}}
+		}
 		// If Autosize is set to True,then break up the string to multiple cells
-		t.Data = t.wrapCellData(cols)
+		if data, err = t.wrapCellData(ctx, data, cols); err != nil {
+			return nil, nil, err
+		}
+		if wrapHeaders {
+			if t.headerRows, err = t.wrapCellData(ctx, t.headerRows, cols); err != nil {
+				return nil, nil, err
+			}
+		}
+		// Columns that are not wrapped keep their natural width
+		if len(t.WrapColumns) > 0 {
+			for i, w := range t.getWidths(measured, data) {
+				if w > cols[i] {
+					cols[i] = w
+				}
+			}
+		}
 	} else {
 		// If WrapStrings is set to True,then break up the string to multiple cells
-		if t.WrapStrings {
-			t.Data = t.wrapCellData([]int{})
+		// Verbatim cells are always split at their newlines
+		if t.wrapsCells() || t.hasVerbatim() {
+			if data, err = t.wrapCellData(ctx, data, []int{}); err != nil {
+				return nil, nil, err
+			}
+		}
+		// Wrap the headers like the cells, the widest lines of the headers are measured
+		if wrapHeaders && t.wrapsCells() {
+			if t.headerRows, err = t.wrapCellData(ctx, t.headerRows, []int{}); err != nil {
+				return nil, nil, err
+			}
+			measured = make([]string, len(t.headers))
+			for _, row := range t.headerRows {
+				for i, line := range row.Elements {
+					if i < len(measured) && t.stringWidth(line) > t.stringWidth(measured[i]) {
+						measured[i] = line
+					}
+				}
+			}
 		}
 		// get max size for each column
-		cols = t.getWidths(t.Headers, t.Data)
+		cols = t.getWidths(measured, data)
+		if err := renderCanceled(ctx); err != nil {
+			return nil, nil, err
+		}
+		// The columns given as a percentage take their width, unless a line is wider
+		for i, w := range t.percentWidths {
+			if w > cols[i] {
+				cols[i] = w
+			}
+		}
+		cols = t.clampWidths(cols)
+	}
+
+	// Widen the columns to fit the footer and the grand total
+	for _, footer := range [][]string{t.footer, t.total} {
+		for i, f := range footer {
+			if i < len(cols) && t.stringWidth(f) > cols[i] {
+				cols[i] = t.stringWidth(f)
+			}
+		}
+	}
+
+	// Cut the headers wider than their column
+	if t.ColumnWidthMode == "content" && !t.VerticalHeaders {
+		headers := make([]string, len(t.headers))
+		for i, header := range t.headers {
+			headers[i] = header
+			if i < len(cols) && t.stringWidth(header) > cols[i] {
+				headers[i] = t.truncate(header, cols[i])
+			}
+		}
+		t.headers = headers
+		t.headerRows = []*TabulateRow{{Elements: headers}}
+	}
+
+	// Number the logical rows and add the width of the index column
+	if len(t.IndexColumn) > 0 {
+		cols = append([]int{t.numberRows(data)}, cols...)
+	}
+	// The blank lines around the rows are added once they are numbered, so they have no index
+	data = t.padRowsVertically(data)
+	return cols, data, nil
+}
+
+// Get the natural width of each column, before the cells are wrapped or the columns autosized
+// The widths are measured on the formatted cells, without rendering or changing the table
+func (t *Tabulate) NaturalWidths() []int {
+	if err := t.validate(); err != nil {
+		return nil
+	}
+	return t.getWidths(t.formattedRows())
+}
+
+// Get the transformed headers and the formatted data rows, before the cells are wrapped or measured
+// Nothing of the table is changed, the table must be valid
+func (t *Tabulate) formattedRows() ([]string, []*TabulateRow) {
+	headers, data := t.Headers, t.Data
+	if len(headers) < 1 {
+		headers, data = data[0].Elements, data[1:]
+	}
+	if diff := len(data[0].Elements) - len(headers); diff > 0 {
+		headers = append(make([]string, diff), headers...)
+	}
+	return t.limitHeaders(t.transformHeaders(headers)), t.formatCells(data)
+}
+
+// Format and clean the cells for rendering, on copies of the rows
+// Only the rows are formatted, the headers and the footer are cut with limitHeaders and limitFooter
+func (t *Tabulate) formatCells(data []*TabulateRow) []*TabulateRow {
+	data = t.applyColumnTypes(data)
+	data = t.trimTrailingZeros(data)
+	data = t.applyColumnFormatters(data)
+	data = t.applyColumnAffixes(data)
+	data = t.markChanges(data)
+	data = t.sparseColumns(data)
+	data = t.colorRows(data)
+	data = t.limitColumns(data)
+	data = t.stripZeroWidth(data)
+	data = t.escapeControl(data)
+	data = t.expandTabs(data)
+	data = t.alignOnChars(data)
+	data = t.alignCurrencies(data)
+	data = t.truncateCells(data)
+	data = t.markNotes(data)
+	data = t.escapeSeparators(data)
+	return data
+}
+
+// Render aligned columns separated by delim, without any lines
+// Occurrences of delim inside the formatted cells and the empty strings are percent-encoded (as is "%" itself),
+// so delim only ever appears between columns. delim must not contain "%" or hex digits
+func (t *Tabulate) RenderWithSeparator(delim string) string {
+	// The cells are escaped once formatted, the settings are restored after rendering
+	headers, data, format, separators := t.Headers, t.Data, t.TableFormat, t.ColumnSeparators
+	empty, columnEmpty := t.EmptyVar, t.ColumnEmptyStrings
+	defer func() {
+		t.Headers, t.Data, t.TableFormat, t.ColumnSeparators = headers, data, format, separators
+		t.EmptyVar, t.ColumnEmptyStrings = empty, columnEmpty
+		t.escapedSeparator = ""
+	}()
+	t.ColumnSeparators = nil
+	t.escapedSeparator = delim
+	t.EmptyVar = escapeSeparator(empty, delim)
+	if len(columnEmpty) > 0 {
+		t.ColumnEmptyStrings = make(map[int]string, len(columnEmpty))
+		for column, e := range columnEmpty {
+			t.ColumnEmptyStrings[column] = escapeSeparator(e, delim)
+		}
 	}
 
+	t.TableFormat = TableFormat{
+		HeaderRow: Row{"", delim, ""},
+		DataRow:   Row{"", delim, ""},
+		Padding:   1,
+	}
+
+	cols, rows := t.prepare()
+	padded_widths := t.getPaddedWidths(cols)
+	lines := t.buildHeader(padded_widths, cols)
+	logical := 0
+	for _, element := range rows {
+		lines = append(lines, t.buildRow(t.padRow(t.withIndex(element.Elements, element.getIndex())), padded_widths, cols, t.TableFormat.DataRow, element, logical))
+		if !element.Continuous {
+			logical++
+		}
+	}
+	return joinLines(lines)
+}
+
+// Add the padding of each column to the column widths
+func (t *Tabulate) getPaddedWidths(cols []int) []int {
 	padded_widths := make([]int, len(cols))
 	for i, _ := range padded_widths {
-		padded_widths[i] = cols[i] + MIN_PADDING*t.TableFormat.Padding
+		padded_widths[i] = cols[i] + t.getMinPadding()*t.getPadding(i)
 	}
+	return padded_widths
+}
+
+// Build the lines of the table for the given data rows
+func (t *Tabulate) buildTable(data []*TabulateRow, cols []int) []string {
+	lines, _ := t.buildTableContext(context.Background(), data, cols)
+	return lines
+}
+
+// Build the lines of the table, returning an error if ctx is done before they are built
+func (t *Tabulate) buildTableContext(ctx context.Context, data []*TabulateRow, cols []int) ([]string, error) {
+	var lines []string
+
+	padded_widths := t.getPaddedWidths(cols)
 
 	// Start appending lines
 
 	// Append top line if not hidden
-	if !inSlice("top", t.HideLines) {
+	if !t.hidesLine("top") {
 		lines = append(lines, t.buildLine(padded_widths, cols, t.TableFormat.LineTop))
 	}
 
-	// Add Header
-	lines = append(lines, t.buildRow(t.padRow(t.Headers, t.TableFormat.Padding), padded_widths, cols, t.TableFormat.HeaderRow))
+	// Add Header, on several lines if it is wrapped
+	headerStart := len(lines)
+	lines = append(lines, t.buildHeader(padded_widths, cols)...)
+	headerEnd := len(lines)
 
 	// Add Line Below Header if not hidden
-	if !inSlice("belowheader", t.HideLines) {
-		lines = append(lines, t.buildLine(padded_widths, cols, t.TableFormat.LineBelowHeader))
+	if !t.hidesLine("belowheader") {
+		lines = append(lines, t.buildLine(padded_widths, cols, t.getLineBelowHeader()))
 	}
 
 	// Add Data Rows
-	for index, element := range t.Data {
-		lines = append(lines, t.buildRow(t.padRow(element.Elements, t.TableFormat.Padding), padded_widths, cols, t.TableFormat.DataRow))
-		if index < len(t.Data)-1 {
+	logical := 0
+	for index, element := range data {
+		if err := renderCanceled(ctx); err != nil {
+			return nil, err
+		}
+		lines = append(lines, t.buildRow(t.padRow(t.withIndex(element.Elements, element.getIndex())), padded_widths, cols, t.TableFormat.DataRow, element, logical))
+		if index < len(data)-1 {
 			if element.Continuous != true {
-				lines = append(lines, t.buildLine(padded_widths, cols, t.TableFormat.LineBetweenRows))
+				logical++
+				line, separator := t.getLineAfter(element), element.Separator
+				// Group the rows with a visible line every SeparatorEvery rows
+				if t.SeparatorEvery > 0 && logical%t.SeparatorEvery == 0 {
+					line, separator = t.getLineAfter(&TabulateRow{Separator: true}), true
+				}
+				if separator || !t.hidesLine("betweenrows") {
+					lines = append(lines, t.buildLine(padded_widths, cols, line))
+				}
 			}
 		}
 	}
 
-	if !inSlice("bottomLine", t.HideLines) {
+	// Add Footer and then the grand total, each below a line like the one below the header
+	for _, f := range [][]string{t.footer, t.total} {
+		if len(f) < 1 {
+			continue
+		}
+		footer := make([]string, len(cols)-t.indexOffset())
+		copy(footer, f)
+		footer = t.withIndex(footer, "")
+		lines = append(lines, t.buildLine(padded_widths, cols, t.getLineBelowHeader()))
+		lines = append(lines, t.buildRow(t.padRow(footer), padded_widths, cols, t.TableFormat.HeaderRow, nil, 0))
+	}
+
+	if !t.hidesLine("bottomLine") {
 		lines = append(lines, t.buildLine(padded_widths, cols, t.TableFormat.LineBottom))
 	}
 
-	// Join lines
-	var buffer bytes.Buffer
-	for _, line := range lines {
-		buffer.WriteString(line + "\n")
+	// Comment out the header of borderless formats, the other lines are indented to stay aligned
+	if len(t.HeaderCommentPrefix) > 0 && len(t.TableFormat.HeaderRow.begin) < 1 && len(t.TableFormat.DataRow.begin) < 1 {
+		indent := strings.Repeat(" ", t.stringWidth(t.HeaderCommentPrefix))
+		for i, line := range lines {
+			if i >= headerStart && i < headerEnd {
+				lines[i] = t.HeaderCommentPrefix + line
+			} else if len(line) > 0 {
+				lines[i] = indent + line
+			}
+		}
 	}
 
-	return buffer.String()
+	// Add the number of rows below the table
+	if t.ShowRowCount {
+		lines = append(lines, t.getRowCount(data))
+	}
+
+	// Add the notes below the table
+	return append(lines, t.notes...), nil
 }
 
-// Calculate the max column width for each element
-func (t *Tabulate) getWidths(headers []string, data []*TabulateRow) []int {
-	widths := make([]int, len(headers))
-	current_max := len(t.EmptyVar)
-	for i := 0; i < len(headers); i++ {
-		current_max = runewidth.StringWidth(headers[i])
-		for _, item := range data {
-			if len(item.Elements) > i && len(widths) > i {
-				element := item.Elements[i]
-				strLength := runewidth.StringWidth(element)
-				if strLength > current_max {
-					widths[i] = strLength
-					current_max = strLength
-				} else {
-					widths[i] = current_max
-				}
-			}
+// Get the summary line with the number of logical rows, wrapped rows are counted once
+func (t *Tabulate) getRowCount(data []*TabulateRow) string {
+	count := 0
+	continuation := false
+	for _, row := range data {
+		if !continuation {
+			count++
 		}
+		continuation = row.Continuous
 	}
-	return widths
+	if len(t.RowCountFormat) > 0 {
+		return fmt.Sprintf(t.RowCountFormat, count)
+	}
+	if count == 1 {
+		return "(1 row)"
+	}
+	return fmt.Sprintf("(%d rows)", count)
 }
 
-// autoSize columns relative to current terminal size
-func (t *Tabulate) autoSize(headers []string, cols []int) []int {
-	// get total size of columns
-	totalWidth := 0
-	for i := range cols {
-		totalWidth += cols[i]
+// Build the lines of the header, the index column label is on the first line
+func (t *Tabulate) buildHeader(padded_widths []int, cols []int) []string {
+	var lines []string
+	blank := t.buildRow(t.padRow(make([]string, len(padded_widths))), padded_widths, cols, t.TableFormat.HeaderRow, nil, 0)
+	for i := 0; i < t.HeaderVerticalPadding[0]; i++ {
+		lines = append(lines, blank)
 	}
-	// get terminal size
+	for i, row := range t.headerRows {
+		label := ""
+		if i == 0 {
+			label = t.IndexColumn
+		}
+		elements := t.padRow(t.withIndex(row.Elements, label))
+		if t.MergeEqualHeaders {
+			lines = append(lines, t.buildMergedHeader(elements, padded_widths, t.TableFormat.HeaderRow))
+		} else {
+			lines = append(lines, t.buildRow(elements, padded_widths, cols, t.TableFormat.HeaderRow, nil, 0))
+		}
+	}
+	for i := 0; i < t.HeaderVerticalPadding[1]; i++ {
+		lines = append(lines, blank)
+	}
+	return lines
+}
+
+// Build a header line where the runs of adjacent columns with the same header share one centered cell
+// The width of a shared cell is the width of its columns and of the separators between them
+func (t *Tabulate) buildMergedHeader(elements []string, padded_widths []int, d Row) string {
+	var buffer bytes.Buffer
+	buffer.WriteString(d.begin)
+	order := t.columnOrder(len(padded_widths))
+	for k := 0; k < len(order); k++ {
+		i := order[k]
+		// find the last shown column with the same header
+		last := k
+		for i >= t.indexOffset() && last+1 < len(order) {
+			header, next := t.headers[i-t.indexOffset()], t.headers[order[last+1]-t.indexOffset()]
+			if len(header) < 1 || header != next {
+				break
+			}
+			last++
+		}
+		element := ""
+		if i < len(elements) {
+			element = elements[i]
+		}
+		if last == k {
+			buffer.WriteString(t.getAlignFunc(t.getColumnAlign(i, t.getHeaderAlign()), t.getFillChar())(padded_widths[i], element))
+		} else {
+			width := padded_widths[i]
+			for j := k; j < last; j++ {
+				width += t.stringWidth(t.getSeparator(columnGap(order, j), d)) + padded_widths[order[j+1]]
+			}
+			buffer.WriteString(t.padCenter(width, element, t.getFillChar()))
+		}
+		if last != len(order)-1 {
+			buffer.WriteString(t.getSeparator(columnGap(order, last), d))
+		}
+		k = last
+	}
+	buffer.WriteString(d.end)
+	return buffer.String()
+}
+
+// Get the order the columns are shown in, the index column stays first when the columns are reversed
+// The hidden empty columns are left out
+func (t *Tabulate) columnOrder(n int) []int {
+	var order []int
+	for i := 0; i < n; i++ {
+		if i < t.indexOffset() || !t.hidden[i-t.indexOffset()] {
+			order = append(order, i)
+		}
+	}
+	if t.ReverseColumns {
+		for i, j := t.indexOffset(), len(order)-1; i < j; i, j = i+1, j-1 {
+			order[i], order[j] = order[j], order[i]
+		}
+	}
+	return order
+}
+
+// Get the gap between the shown columns k and k+1, as the gap between the same columns in the data
+func columnGap(order []int, k int) int {
+	if order[k] < order[k+1] {
+		return order[k]
+	}
+	return order[k+1]
+}
+
+// Get the line drawn after a data row
+// A row with Separator set forces a visible line, even if the format has no line between rows
+func (t *Tabulate) getLineAfter(row *TabulateRow) Line {
+	if row.Separator && t.TableFormat.LineBetweenRows == (Line{}) {
+		return t.getLineBelowHeader()
+	}
+	return withHline(t.TableFormat.LineBetweenRows, t.RowSeparatorChar)
+}
+
+// Get the line drawn below the header, with the fill set by SetHeaderSeparatorChar
+func (t *Tabulate) getLineBelowHeader() Line {
+	return withHline(t.TableFormat.LineBelowHeader, t.HeaderSeparatorChar)
+}
+
+// Replace the fill of a line, keeping its junctions. Missing lines and a zero fill are left as they are
+func withHline(l Line, fill rune) Line {
+	if fill != 0 && l != (Line{}) {
+		l.hline = string(fill)
+	}
+	return l
+}
+
+// Fold the built lines, pass them to the line hook and join them into the rendered table
+func (t *Tabulate) output(lines []string) string {
+	lines = t.foldLines(lines)
+	if t.LineHook != nil {
+		for i, line := range lines {
+			t.LineHook(i, line)
+		}
+	}
+	return joinLines(lines)
+}
+
+// Fold the lines wider than HardWrapWidth onto the next lines, starting at the left edge of the table
+func (t *Tabulate) foldLines(lines []string) []string {
+	if t.HardWrapWidth < 1 {
+		return lines
+	}
+	var folded []string
+	for _, line := range lines {
+		for t.stringWidth(line) > t.HardWrapWidth {
+			fragment := t.truncate(line, t.HardWrapWidth)
+			// always move forward, even if the first rune is wider than the line
+			if len(fragment) < 1 {
+				_, size := utf8.DecodeRuneInString(line)
+				fragment = line[:size]
+			}
+			folded = append(folded, fragment)
+			line = line[len(fragment):]
+		}
+		folded = append(folded, line)
+	}
+	return folded
+}
+
+// Join lines into the rendered table
+func joinLines(lines []string) string {
+	var buffer bytes.Buffer
+	for _, line := range lines {
+		buffer.WriteString(line + "\n")
+	}
+
+	return buffer.String()
+}
+
+// Calculate the max column width for each element
+func (t *Tabulate) getWidths(headers []string, data []*TabulateRow) []int {
+	widths := make([]int, len(headers))
+	for i := 0; i < len(headers); i++ {
+		current_max := t.stringWidth(headers[i])
+		for _, item := range data {
+			// short rows filled on the left hold the last columns
+			e := i
+			if t.RaggedFill == "left" && len(item.Elements) < len(headers) {
+				e -= len(headers) - len(item.Elements)
+			}
+			// missing cells are as wide as the empty string of their column
+			if (e < 0 || len(item.Elements) <= e || item.Elements[e] == "nil") && len(widths) > i {
+				if empty := t.stringWidth(t.getEmptyString(i)); empty > current_max {
+					widths[i] = empty
+					current_max = empty
+				} else {
+					widths[i] = current_max
+				}
+			} else if e >= 0 && len(item.Elements) > e && len(widths) > i {
+				element := item.Elements[e]
+				strLength := t.cellWidth(element)
+				if strLength > current_max {
+					widths[i] = strLength
+					current_max = strLength
+				} else {
+					widths[i] = current_max
+				}
+			}
+		}
+	}
+	return widths
+}
+
+// Get the width available to the table, the terminal width unless MaxTableWidth is set
+// Reading the terminal size cannot be interrupted: ctx is only checked before it, and the renders
+// wait for each other since termbox keeps the terminal in global state. Set MaxTableWidth to avoid it
+func (t *Tabulate) tableWidth(ctx context.Context) (int, error) {
+	if t.MaxTableWidth > 0 {
+		return t.MaxTableWidth, nil
+	}
+	if err := renderCanceled(ctx); err != nil {
+		return 0, err
+	}
+	termboxMutex.Lock()
+	defer termboxMutex.Unlock()
+	// get terminal size
 	if err := termbox.Init(); err != nil {
-		panic(err)
+		return 0, err
 	}
-	fullWidth, _ := termbox.Size()
+	width, _ := termbox.Size()
 	termbox.Close()
+	return width, nil
+}
+
+// Resolve the width of the columns given as a percentage of the table width
+// The padding and the separator of a column are part of its share of the width
+func (t *Tabulate) resolvePercentWidths(columns int, fullWidth int) map[int]int {
+	if len(t.ColumnWidthPercents) < 1 {
+		return nil
+	}
+	widths := make(map[int]int)
+	for i, pct := range t.ColumnWidthPercents {
+		if i < 0 || i >= columns {
+			continue
+		}
+		width := int(math.Floor(float64(fullWidth)*pct/100)) - 1 - t.getMinPadding()*t.getPadding(i+t.indexOffset())
+		if width < 1 {
+			width = 1
+		}
+		widths[i] = width
+	}
+	return widths
+}
+
+// autoSize columns relative to the width available to the table
+func (t *Tabulate) autoSize(headers []string, cols []int, fullWidth int) []int {
+	// get total size of columns
+	totalWidth := 0
+	for i := range cols {
+		totalWidth += cols[i]
+	}
 	// removing size of characters drawing the columns and padding
-	fullWidth -= 2 + (len(cols))*(1+t.TableFormat.Padding*MIN_PADDING)
+	fullWidth -= 2
+	for i := range cols {
+		if !t.hidden[i] {
+			fullWidth -= 1 + t.getPadding(i+t.indexOffset())*t.getMinPadding()
+		}
+	}
+	if len(t.IndexColumn) > 0 {
+		fullWidth -= 1 + t.getPadding(0)*t.getMinPadding() + t.stringWidth(t.IndexColumn)
+	}
 
 	// shrink or expand columns while keeping proportions
 	ratio := float64(fullWidth) / float64(totalWidth)
@@ -352,46 +1419,696 @@ func (t *Tabulate) autoSize(headers []string, cols []int) []int {
 			// do not shrink the smaller columns
 			if float64(cols[i]) < averageSize {
 				// get amount of width that could not be removed from this column
-				unshrinkableColumnsWidth += cols[i] + MIN_PADDING*t.TableFormat.Padding
+				unshrinkableColumnsWidth += cols[i] + t.getMinPadding()*t.getPadding(i+t.indexOffset())
 				// calculate new ratio taking this into account
 				ratio = float64(fullWidth-unshrinkableColumnsWidth) / float64(totalWidth-unshrinkableColumnsWidth)
 			} else {
 				newSize := int(math.Floor(float64(cols[i]) * ratio))
 				// ensure minimum size:
-				if newSize < runewidth.StringWidth(headers[i]) {
+				if newSize < t.stringWidth(headers[i]) {
 					// get amount of width that could not be removed from this column
-					unshrinkableColumnsWidth += runewidth.StringWidth(headers[i]) - cols[i] + MIN_PADDING*t.TableFormat.Padding
+					unshrinkableColumnsWidth += t.stringWidth(headers[i]) - cols[i] + t.getMinPadding()*t.getPadding(i+t.indexOffset())
 					// calculate new ratio taking this into account
 					ratio = float64(fullWidth-unshrinkableColumnsWidth) / float64(totalWidth-unshrinkableColumnsWidth)
 					// set min column width
-					cols[i] = runewidth.StringWidth(headers[i])
+					cols[i] = t.stringWidth(headers[i])
 				} else {
 					shrinkable[i] = true
 				}
 			}
-		}
-		// reshrink with latest ratio
-		for i := range cols {
-			if shrinkable[i] {
-				cols[i] = int(math.Floor(float64(cols[i]) * ratio))
+		}
+		// reshrink with latest ratio
+		for i := range cols {
+			if shrinkable[i] {
+				cols[i] = int(math.Floor(float64(cols[i]) * ratio))
+			}
+		}
+		// shrink the widest columns below their headers if the table still overflows
+		if t.AutoSizeShrinkHeaders > 0 {
+			cols = t.shrinkToFit(cols, fullWidth)
+		}
+	}
+	return cols
+}
+
+// Shrink the widest columns one at a time until they fit in width, none below AutoSizeShrinkHeaders
+func (t *Tabulate) shrinkToFit(cols []int, width int) []int {
+	total := 0
+	for _, c := range cols {
+		total += c
+	}
+	for total > width {
+		widest := -1
+		for i, c := range cols {
+			if c > t.AutoSizeShrinkHeaders && (widest < 0 || c > cols[widest]) {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		cols[widest]--
+		total--
+	}
+	return cols
+}
+
+// Set the minimum width of the headers when autosizing, the headers are cut with … to fit the terminal
+// By default the headers are never cut, and a table with many long headers can be wider than the terminal
+func (t *Tabulate) SetAutoSizeShrinkHeaders(minHeaderWidth int) {
+	t.AutoSizeShrinkHeaders = minHeaderWidth
+}
+
+// Set Headers of the table
+// If Headers count is less than the data row count, the headers will be padded to the right
+func (t *Tabulate) SetHeaders(headers []string) *Tabulate {
+	t.Headers = headers
+	return t
+}
+
+// Set a function transforming the headers before they are measured and rendered
+// The headers are rendered as they are when unset
+func (t *Tabulate) SetHeaderTransform(fn func(string) string) {
+	t.HeaderTransform = fn
+}
+
+// Set an icon rendered before the header of a column, separated by a space
+// The column is widened to fit the icon
+func (t *Tabulate) SetHeaderIcon(index int, icon string) {
+	if t.HeaderIcons == nil {
+		t.HeaderIcons = make(map[int]string)
+	}
+	t.HeaderIcons[index] = icon
+}
+
+// Set a comment marker such as "# " starting the header line in formats without borders
+// The other lines are indented by the width of the marker, so the columns stay aligned
+func (t *Tabulate) SetHeaderCommentPrefix(prefix string) {
+	t.HeaderCommentPrefix = prefix
+}
+
+// Render the headers in upper case
+func (t *Tabulate) UpperHeaders() {
+	t.SetHeaderTransform(strings.ToUpper)
+}
+
+// Render the headers in title case
+func (t *Tabulate) TitleHeaders() {
+	t.SetHeaderTransform(titleCase)
+}
+
+// Get the headers to render, transformed if a transform is set, after their icons and before their sort indicators
+func (t *Tabulate) transformHeaders(headers []string) []string {
+	if t.HeaderTransform == nil && len(t.HeaderIcons) < 1 && (!t.ShowSortIndicators || len(t.sortKeys) < 1) {
+		return headers
+	}
+	transformed := make([]string, len(headers))
+	for i, header := range headers {
+		if t.HeaderTransform != nil {
+			header = t.HeaderTransform(header)
+		}
+		if icon, ok := t.HeaderIcons[i]; ok {
+			header = icon + " " + header
+		}
+		transformed[i] = header + t.getSortIndicator(i)
+	}
+	return transformed
+}
+
+// Set the character of the line below the header, instead of the one of the format
+// The junctions of the format are kept
+func (t *Tabulate) SetHeaderSeparatorChar(r rune) {
+	t.HeaderSeparatorChar = r
+}
+
+// Set the character of the lines between rows, instead of the one of the format
+// The junctions of the format are kept
+func (t *Tabulate) SetRowSeparatorChar(r rune) {
+	t.RowSeparatorChar = r
+}
+
+// Set if the headers are rendered vertically, one character per line, to keep the columns narrow
+func (t *Tabulate) SetVerticalHeaders(vertical bool) {
+	t.VerticalHeaders = vertical
+}
+
+// Get the lines of the vertical headers, and the widest character of each header
+func (t *Tabulate) verticalHeaders() ([]*TabulateRow, []string) {
+	var rows []*TabulateRow
+	widest := make([]string, len(t.headers))
+	for i, header := range t.headers {
+		for line, r := range []rune(header) {
+			if line >= len(rows) {
+				rows = append(rows, &TabulateRow{Elements: make([]string, len(t.headers))})
+			}
+			rows[line].Elements[i] = string(r)
+			if t.stringWidth(string(r)) > t.stringWidth(widest[i]) {
+				widest[i] = string(r)
+			}
+		}
+	}
+	if len(rows) < 1 {
+		rows = []*TabulateRow{{Elements: make([]string, len(t.headers))}}
+	}
+	return rows, widest
+}
+
+// Set if adjacent columns with the same header share a single centered header cell
+// The data rows keep their columns
+func (t *Tabulate) SetMergeEqualHeaders(merge bool) {
+	t.MergeEqualHeaders = merge
+}
+
+// Set if the columns are shown in reverse order, the last column first
+// The data and the settings of the columns are unchanged, only the order of the rendered columns
+func (t *Tabulate) SetReverseColumns(reverse bool) {
+	t.ReverseColumns = reverse
+}
+
+// Set a line after every n rows, to group them in formats without lines between rows
+// Wrapped rows count as one row
+func (t *Tabulate) SetSeparatorEvery(n int) {
+	t.SeparatorEvery = n
+}
+
+// Set if control characters in cells are replaced with a visible escape, like ^G for the bell
+// This keeps untrusted data from corrupting the terminal or the widths of the columns
+func (t *Tabulate) SetEscapeControl(escape bool) {
+	t.EscapeControl = escape
+}
+
+// Set a function called with every rendered line and its index, e.g. to log the table line by line
+// The function observes the lines, it does not change the output
+func (t *Tabulate) SetLineHook(fn func(lineIndex int, line string)) {
+	t.LineHook = fn
+}
+
+// Set the maximum width of the rendered lines, wider lines are folded onto the next lines
+// This is a last resort for narrow terminals, SetAutoSize wraps the cells instead
+func (t *Tabulate) SetHardWrapWidth(n int) {
+	t.HardWrapWidth = n
+}
+
+// Set what drives the width of the columns, header (default) or content
+// With content, the width of a column only depends on its cells and a wider header is cut
+func (t *Tabulate) SetColumnWidthMode(mode string) {
+	t.ColumnWidthMode = mode
+}
+
+// Set if every row must have as many cells as there are headers
+// Rendering a table with a shorter or longer row then fails, instead of filling the missing cells with the empty string
+func (t *Tabulate) SetStrictColumns(strict bool) {
+	t.StrictColumns = strict
+}
+
+// Set if the columns without any data are hidden, with their headers
+// The data of the table is unchanged
+func (t *Tabulate) SetHideEmptyColumns(hide bool) {
+	t.HideEmptyColumns = hide
+}
+
+// Get the columns where every cell is empty, none if every column is empty
+func (t *Tabulate) getEmptyColumns(data []*TabulateRow) map[int]bool {
+	if !t.HideEmptyColumns {
+		return nil
+	}
+	empty := make(map[int]bool)
+	for i := range t.headers {
+		empty[i] = true
+	}
+	for _, row := range data {
+		for i, e := range row.Elements {
+			if e = strings.TrimSpace(e); len(e) > 0 && e != "nil" {
+				delete(empty, i)
+			}
+		}
+	}
+	if len(empty) == len(t.headers) {
+		return nil
+	}
+	return empty
+}
+
+// Set the maximum number of columns to render, a column of … marks the hidden columns
+// All columns are rendered when unset
+func (t *Tabulate) SetMaxColumns(n int) {
+	t.MaxColumns = n
+}
+
+// Keep the first MaxColumns columns, on copies of the rows
+// A … column is added to show that columns are hidden
+func (t *Tabulate) limitColumns(data []*TabulateRow) []*TabulateRow {
+	n := t.MaxColumns
+	if n < 1 {
+		return data
+	}
+	limited := make([]*TabulateRow, len(data))
+	for i, row := range data {
+		limited[i] = row
+		if len(row.Elements) > n {
+			limited[i] = row.clone()
+			limited[i].Elements = append(limited[i].Elements[:n], "…")
+		}
+	}
+	return limited
+}
+
+// Keep the first MaxColumns headers, with a … header showing that columns are hidden
+func (t *Tabulate) limitHeaders(headers []string) []string {
+	n := t.MaxColumns
+	if n < 1 || len(headers) <= n {
+		return headers
+	}
+	return append(headers[:n:n], "…")
+}
+
+// Keep the cells of the first MaxColumns columns of a footer line
+func (t *Tabulate) limitFooter(footer []string) []string {
+	if t.MaxColumns < 1 || len(footer) <= t.MaxColumns {
+		return footer
+	}
+	return footer[:t.MaxColumns]
+}
+
+// Set if invisible characters are removed from the cells: zero width spaces and joiners,
+// byte order marks and soft hyphens. Joiners inside emoji sequences are kept
+func (t *Tabulate) SetStripZeroWidth(strip bool) {
+	t.StripZeroWidth = strip
+}
+
+// Set the width of the tab stops, tabs in cells are expanded to spaces up to the next stop
+// Tabs are left as they are by default
+func (t *Tabulate) SetTabWidth(n int) {
+	t.TabWidth = n
+}
+
+// Set a function formatting the cells of a column, before the widths are computed
+// The header of the column is not formatted
+func (t *Tabulate) SetColumnFormatter(index int, fn func(string) string) {
+	if t.ColumnFormatters == nil {
+		t.ColumnFormatters = make(map[int]func(string) string)
+	}
+	t.ColumnFormatters[index] = fn
+}
+
+// Set a prefix added to the cells of a column, such as a currency symbol. Empty cells have no prefix
+func (t *Tabulate) SetColumnPrefix(index int, prefix string) {
+	if t.ColumnPrefixes == nil {
+		t.ColumnPrefixes = make(map[int]string)
+	}
+	t.ColumnPrefixes[index] = prefix
+}
+
+// Set a suffix added to the cells of a column, such as a unit. Empty cells have no suffix
+func (t *Tabulate) SetColumnSuffix(index int, suffix string) {
+	if t.ColumnSuffixes == nil {
+		t.ColumnSuffixes = make(map[int]string)
+	}
+	t.ColumnSuffixes[index] = suffix
+}
+
+// Set the side of the columns missing from rows shorter than the table, left or right (default)
+func (t *Tabulate) SetRaggedFill(side string) {
+	t.RaggedFill = side
+}
+
+// Align each cell of a column with match if it matches the pattern, and with noMatch otherwise
+// e.g. SetAlignByRegex(1, `^-?[0-9.]+$`, "right", "left") aligns numbers right and text left
+func (t *Tabulate) SetAlignByRegex(column int, pattern string, match, noMatch string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	if t.AlignByRegex == nil {
+		t.AlignByRegex = make(map[int]RegexAlign)
+	}
+	t.AlignByRegex[column] = RegexAlign{Pattern: re, Match: match, NoMatch: noMatch}
+	return nil
+}
+
+// Align the cells of a column on the first occurrence of a character, such as ':' in times or '=' in assignments
+// Cells without the character are aligned as if they had it at their end
+func (t *Tabulate) SetAlignOnChar(column int, ch rune) {
+	if t.AlignChars == nil {
+		t.AlignChars = make(map[int]rune)
+	}
+	t.AlignChars[column] = ch
+}
+
+// Set the currency symbol of a column, rendered at the left of its cells while the amounts are aligned right
+// A symbol already starting a cell is moved too, so "$5" and "1,234" both line up after the symbol
+func (t *Tabulate) SetCurrencyColumn(index int, symbol string) {
+	if t.CurrencySymbols == nil {
+		t.CurrencySymbols = make(map[int]string)
+	}
+	t.CurrencySymbols[index] = symbol
+}
+
+// Set the type of each column, which sets the default align and formatting of its values
+// Numbers are aligned right, other types left. An align set with SetAlign still applies to all columns
+// Values of integer columns with a fraction are not rounded, they are left as they are
+func (t *Tabulate) SetColumnTypes(types []ColumnType) {
+	t.ColumnTypes = types
+}
+
+// Set a function choosing the character each data cell is filled with when aligned, by logical row and column
+// Characters that are not one column wide are ignored, and the cell is filled as set by SetFillChar
+func (t *Tabulate) SetPadFillFunc(fn func(row, col int, content string) rune) {
+	t.PadFillFunc = fn
+}
+
+// Set the character the cells are filled with when aligned, such as '.' for dot leaders
+// The character must be one column wide to keep the alignment, other characters are ignored
+func (t *Tabulate) SetFillChar(r rune) {
+	if runewidth.RuneWidth(r) != 1 {
+		return
+	}
+	t.FillChar = r
+}
+
+// Attach a note to the cell at row and column of the data
+// The cell gets a superscript marker, and the note is listed below the table
+func (t *Tabulate) AddNote(row, column int, note string) {
+	t.Notes = append(t.Notes, Note{Row: row, Column: column, Text: note})
+}
+
+// Mark the cells with notes, on copies of the rows
+func (t *Tabulate) markNotes(data []*TabulateRow) []*TabulateRow {
+	notes := t.cellNotes(data)
+	if len(notes) < 1 {
+		return data
+	}
+	marked := make([]*TabulateRow, len(data))
+	copy(marked, data)
+	for i, note := range notes {
+		if marked[note.Row] == data[note.Row] {
+			marked[note.Row] = data[note.Row].clone()
+		}
+		marked[note.Row].Elements[note.Column] += superscript(i + 1)
+	}
+	return marked
+}
+
+// Get the lines of the notes rendered below the table, numbered like their markers
+func (t *Tabulate) getNotes(data []*TabulateRow) []string {
+	var lines []string
+	for i, note := range t.cellNotes(data) {
+		lines = append(lines, superscript(i+1)+" "+note.Text)
+	}
+	return lines
+}
+
+// Get the notes of the cells of the data, the notes of cells outside the data are dropped
+func (t *Tabulate) cellNotes(data []*TabulateRow) []Note {
+	var notes []Note
+	for _, note := range t.Notes {
+		if note.Row < 0 || note.Row >= len(data) || note.Column < 0 || note.Column >= len(data[note.Row].Elements) {
+			continue
+		}
+		notes = append(notes, note)
+	}
+	return notes
+}
+
+// Set footer values computed over the numeric cells of columns, indexed by column
+// Available functions: sum, avg, min, max, count. Non-numeric and empty cells are skipped.
+// The footer is rendered below the data in the style of the header, columns out of range are ignored
+func (t *Tabulate) SetAutoFooter(funcs map[int]string) {
+	t.AutoFooter = funcs
+}
+
+// Get the footer row with the automatic footer values
+func (t *Tabulate) getFooter() []string {
+	if len(t.AutoFooter) < 1 {
+		return nil
+	}
+	footer := make([]string, len(t.headers))
+	for column, fn := range t.AutoFooter {
+		if column >= 0 && column < len(footer) {
+			footer[column] = t.aggregate(column, fn)
+		}
+	}
+	return footer
+}
+
+// Set a grand total row below the table, with label in the first column and the sums of the numeric cells of columns
+// The sums are formatted like the cells of their column, and the row is styled like the header
+func (t *Tabulate) SetGrandTotal(label string, columns []int) {
+	t.GrandTotalLabel = label
+	t.GrandTotalColumns = columns
+}
+
+// Get the grand total row, formatted by the types, formatters and affixes of the columns
+func (t *Tabulate) getGrandTotal() []string {
+	if len(t.GrandTotalColumns) < 1 {
+		return nil
+	}
+	total := &TabulateRow{Elements: make([]string, len(t.headers))}
+	for _, column := range t.GrandTotalColumns {
+		if column >= 0 && column < len(total.Elements) {
+			total.Elements[column] = t.aggregate(column, "sum")
+		}
+	}
+	formatted := t.applyColumnAffixes(t.applyColumnFormatters(t.trimTrailingZeros(t.applyColumnTypes([]*TabulateRow{total}))))[0].Elements
+	// Only the sums are formatted, the other cells are blank
+	for column := range formatted {
+		if len(total.Elements[column]) < 1 {
+			formatted[column] = ""
+		}
+	}
+	if len(formatted) > 0 {
+		formatted[0] = t.GrandTotalLabel
+	}
+	return formatted
+}
+
+// Compute an aggregate function over the numeric cells of a column
+// Unknown functions and aggregates without any numeric cell result in an empty string
+func (t *Tabulate) aggregate(column int, fn string) string {
+	var values []float64
+	for _, row := range t.Data {
+		if column < len(row.Elements) {
+			if f, ok := t.parseNumber(row.Elements[column]); ok {
+				values = append(values, f)
+			}
+		}
+	}
+	if fn == "count" {
+		return strconv.Itoa(len(values))
+	}
+	if len(values) < 1 {
+		return ""
+	}
+
+	result := values[0]
+	switch fn {
+	case "sum", "avg":
+		result = 0
+		for _, v := range values {
+			result += v
+		}
+		if fn == "avg" {
+			result /= float64(len(values))
+		}
+	case "min":
+		for _, v := range values {
+			result = math.Min(result, v)
+		}
+	case "max":
+		for _, v := range values {
+			result = math.Max(result, v)
+		}
+	default:
+		return ""
+	}
+	return t.formatFloat(result)
+}
+
+// Set Float Formatting
+// will be used in strconv.FormatFloat(element, format, -1, 64)
+func (t *Tabulate) SetFloatFormat(format byte) *Tabulate {
+	t.FloatFormat = format
+	t.normalize()
+	return t
+}
+
+// Set if the trailing zeros of the decimals are removed from the float values, such as 1.500 rendered as 1.5
+// The cells of float and currency columns are trimmed too, the other strings are left as they are
+// Combine with SetAlignOnChar to keep the decimal marks of a column aligned
+func (t *Tabulate) SetTrimTrailingZeros(trim bool) {
+	t.TrimTrailingZeros = trim
+}
+
+// Set the layout used to format time.Time values, defaults to time.RFC3339
+func (t *Tabulate) SetDateFormat(layout string) {
+	t.DateFormat = layout
+	t.normalize()
+}
+
+// Set the decimal and grouping marks used to format ints and floats, e.g. SetLocale(',', '.') for 1.234,56
+// A zero grouping mark disables grouping. Defaults to a '.' decimal mark without grouping
+func (t *Tabulate) SetLocale(decimalMark, groupingMark rune) {
+	t.DecimalMark = decimalMark
+	t.GroupingMark = groupingMark
+	t.normalize()
+}
+
+// Set how NaN float values will be represented
+// Defaults to the strconv representation "NaN"
+func (t *Tabulate) SetNaNFormat(format string) {
+	t.NaNFormat = format
+	t.normalize()
+}
+
+// Set how infinite float values will be represented
+// Negative infinity is prefixed with "-". Defaults to the strconv representation "+Inf"
+func (t *Tabulate) SetInfFormat(format string) {
+	t.InfFormat = format
+	t.normalize()
+}
+
+// Set how bool values will be represented, e.g. "✓" and "✗"
+// Defaults to "true" and "false"
+func (t *Tabulate) SetBoolFormat(trueStr, falseStr string) {
+	t.TrueFormat = trueStr
+	t.FalseFormat = falseStr
+	t.normalize()
+}
+
+// Set the ANSI escape codes wrapping cells that hold a negative number
+// e.g. SetNegativeColor("\033[31m", "\033[0m") renders negative numbers in red
+func (t *Tabulate) SetNegativeColor(ansiPrefix, ansiSuffix string) {
+	t.NegativeColorPrefix = ansiPrefix
+	t.NegativeColorSuffix = ansiSuffix
+}
+
+// Set the data of the previous render, the cells that changed since are highlighted
+// Nothing is highlighted when no previous data is set
+func (t *Tabulate) SetPrevious(data [][]string) {
+	t.Previous = data
+}
+
+// Set the ANSI escape codes wrapping the cells that changed since the previous data
+// The cells are shown in reverse video by default
+func (t *Tabulate) SetChangeHighlight(ansiPrefix, ansiSuffix string) {
+	t.ChangePrefix = ansiPrefix
+	t.ChangeSuffix = ansiSuffix
+}
+
+func (t *Tabulate) getChangePrefix() string {
+	if len(t.ChangePrefix) < 1 {
+		return "\033[7m"
+	}
+	return t.ChangePrefix
+}
+
+func (t *Tabulate) getChangeSuffix() string {
+	if len(t.ChangeSuffix) < 1 {
+		return "\033[0m"
+	}
+	return t.ChangeSuffix
+}
+
+// Mark the cells that differ from the previous data, on copies of the rows
+func (t *Tabulate) markChanges(data []*TabulateRow) []*TabulateRow {
+	if t.Previous == nil {
+		return data
+	}
+	marked := make([]*TabulateRow, len(data))
+	for i, row := range data {
+		marked[i] = row.clone()
+		marked[i].changed = make([]bool, len(row.Elements))
+		for column, e := range row.Elements {
+			marked[i].changed[column] = i >= len(t.Previous) || column >= len(t.Previous[i]) || t.Previous[i][column] != e
+		}
+	}
+	return marked
+}
+
+// Set a function choosing the ANSI escape codes wrapping a whole data row, from its cells
+// The row is colored when ok is true, e.g. in red when its status is FAILED
+func (t *Tabulate) SetRowColorFunc(fn func(cells []string) (prefix, suffix string, ok bool)) {
+	t.RowColorFunc = fn
+}
+
+// Get the colors of the rows, on copies of the rows
+func (t *Tabulate) colorRows(data []*TabulateRow) []*TabulateRow {
+	if t.RowColorFunc == nil {
+		return data
+	}
+	colored := make([]*TabulateRow, len(data))
+	for i, row := range data {
+		colored[i] = row
+		if prefix, suffix, ok := t.RowColorFunc(row.Elements); ok {
+			colored[i] = row.clone()
+			colored[i].color = [2]string{prefix, suffix}
+		}
+	}
+	return colored
+}
+
+// Shade the background of the cells of a numeric column, from blue for its lowest value to red for its highest
+// Cells that are not numbers are not shaded
+func (t *Tabulate) SetHeatmapColumn(index int) {
+	t.HeatmapColumns = append(t.HeatmapColumns, index)
+}
+
+// Get the lowest and highest value of each heatmap column
+func (t *Tabulate) getHeatmapRanges(data []*TabulateRow) map[int][2]float64 {
+	ranges := make(map[int][2]float64)
+	for _, column := range t.HeatmapColumns {
+		for _, row := range data {
+			if column < 0 || column >= len(row.Elements) {
+				continue
+			}
+			f, ok := t.parseNumber(row.Elements[column])
+			if !ok {
+				continue
+			}
+			if r, ok := ranges[column]; !ok {
+				ranges[column] = [2]float64{f, f}
+			} else {
+				ranges[column] = [2]float64{math.Min(r[0], f), math.Max(r[1], f)}
 			}
 		}
 	}
-	return cols
+	return ranges
 }
 
-// Set Headers of the table
-// If Headers count is less than the data row count, the headers will be padded to the right
-func (t *Tabulate) SetHeaders(headers []string) *Tabulate {
-	t.Headers = headers
-	return t
+// Wrap a padded cell of a heatmap column in the background color of its value
+func (t *Tabulate) shadeCell(column int, element, output string) string {
+	r, ok := t.heatmap[column]
+	if !ok {
+		return output
+	}
+	f, ok := t.parseNumber(element)
+	if !ok {
+		return output
+	}
+	ratio := 0.0
+	if r[1] > r[0] {
+		ratio = (f - r[0]) / (r[1] - r[0])
+	}
+	color := HEATMAP_COLORS[int(math.Floor(ratio*float64(len(HEATMAP_COLORS)-1)+0.5))]
+	return fmt.Sprintf("\033[48;5;%dm%s\033[0m", color, output)
 }
 
-// Set Float Formatting
-// will be used in strconv.FormatFloat(element, format, -1, 64)
-func (t *Tabulate) SetFloatFormat(format byte) *Tabulate {
-	t.FloatFormat = format
-	return t
+// Set the ANSI escape codes wrapping every data cell, e.g. to dim the data below the headers
+// Cells colored as negative numbers or by a heatmap keep their own color
+func (t *Tabulate) SetDataColor(ansiPrefix, ansiSuffix string) {
+	t.DataColorPrefix = ansiPrefix
+	t.DataColorSuffix = ansiSuffix
+}
+
+// Set the ANSI escape codes wrapping the data cells of every other column, to shade them alternately
+// The first data column is even, cells colored as negative numbers or by a heatmap keep their own color
+func (t *Tabulate) SetColumnZebra(evenPrefix, evenSuffix, oddPrefix, oddSuffix string) {
+	t.ColumnZebraColors = [2][2]string{{evenPrefix, evenSuffix}, {oddPrefix, oddSuffix}}
+}
+
+// Set if the headers are shaded like the data cells of their column by SetColumnZebra
+func (t *Tabulate) SetColumnZebraHeaders(shaded bool) {
+	t.ColumnZebraHeaders = shaded
 }
 
 // Set Align Type, Available options: left, right, center
@@ -399,31 +2116,202 @@ func (t *Tabulate) SetAlign(align string) {
 	t.Align = align
 }
 
-// Select the padding function based on the align type
-func (t *Tabulate) getAlignFunc() func(int, string) string {
-	if len(t.Align) < 1 || t.Align == "right" {
-		return t.padLeft
-	} else if t.Align == "left" {
-		return t.padRight
-	} else {
-		return t.padCenter
+// Set the header of an index column, prepended to the table when rendering
+// The index column numbers the logical rows from 1, continuation rows of wrapped cells are left blank.
+// Column indexes of the other settings do not count the index column
+func (t *Tabulate) SetIndexColumn(header string) {
+	t.IndexColumn = header
+}
+
+// Get the number of columns prepended to the rendered table
+func (t *Tabulate) indexOffset() int {
+	if len(t.IndexColumn) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// Prepend the index cell to a row if the index column is set
+func (t *Tabulate) withIndex(elements []string, index string) []string {
+	if len(t.IndexColumn) < 1 {
+		return elements
+	}
+	return append([]string{index}, elements...)
+}
+
+// Number the logical rows of the data, and return the width of the index column
+func (t *Tabulate) numberRows(data []*TabulateRow) int {
+	number := 0
+	continuation := false
+	for _, row := range data {
+		row.index = 0
+		if !continuation {
+			number++
+			row.index = number
+		}
+		continuation = row.Continuous
+	}
+	width := t.stringWidth(t.IndexColumn)
+	if w := len(strconv.Itoa(number)); w > width {
+		width = w
+	}
+	return width
+}
+
+// Get the index cell of a row, blank for continuation rows
+func (row *TabulateRow) getIndex() string {
+	if row.index < 1 {
+		return ""
+	}
+	return strconv.Itoa(row.index)
+}
+
+// Set the padding of each column, indexed by column
+// Columns without an entry (or with a negative one) use the padding of the table format
+func (t *Tabulate) SetColumnPadding(padding []int) {
+	t.ColumnPadding = padding
+}
+
+// Set the number of blank lines above and below the content of each data row
+// The blank lines are drawn with the borders of the rows, the lines between rows are kept
+func (t *Tabulate) SetRowVerticalPadding(top, bottom int) {
+	t.RowVerticalPadding = [2]int{top, bottom}
+}
+
+// Set the number of blank lines above and below the header
+func (t *Tabulate) SetHeaderVerticalPadding(top, bottom int) {
+	t.HeaderVerticalPadding = [2]int{top, bottom}
+}
+
+// Add the blank lines of the vertical padding around each logical row, as continuation rows
+func (t *Tabulate) padRowsVertically(data []*TabulateRow) []*TabulateRow {
+	top, bottom := t.RowVerticalPadding[0], t.RowVerticalPadding[1]
+	if top < 1 && bottom < 1 {
+		return data
+	}
+	blank := func(row *TabulateRow) *TabulateRow {
+		return &TabulateRow{Elements: make([]string, len(row.Elements)), Separator: row.Separator, Continuous: true, color: row.color}
+	}
+	var padded []*TabulateRow
+	for _, lines := range logicalRows(data) {
+		for i := 0; i < top; i++ {
+			padded = append(padded, blank(lines[0]))
+		}
+		padded = append(padded, lines...)
+		if bottom > 0 {
+			last := lines[len(lines)-1].clone()
+			last.Continuous = true
+			padded[len(padded)-1] = last
+			for i := 0; i < bottom; i++ {
+				padded = append(padded, blank(last))
+			}
+			padded[len(padded)-1].Continuous = lines[len(lines)-1].Continuous
+		}
+	}
+	return padded
+}
+
+// Set the separator of each gap between columns, gap i being between column i and i+1
+// Gaps without an entry (or with an empty one) use the separator of the table format
+func (t *Tabulate) SetColumnSeparators(seps []string) {
+	t.ColumnSeparators = seps
+}
+
+// Get the separator of the gap after a column
+func (t *Tabulate) getSeparator(gap int, d Row) string {
+	gap -= t.indexOffset()
+	if gap >= 0 && gap < len(t.ColumnSeparators) && len(t.ColumnSeparators[gap]) > 0 {
+		return t.ColumnSeparators[gap]
+	}
+	return d.sep
+}
+
+// Get the padding of a column
+func (t *Tabulate) getPadding(column int) int {
+	column -= t.indexOffset()
+	if column >= 0 && column < len(t.ColumnPadding) && t.ColumnPadding[column] >= 0 {
+		return t.ColumnPadding[column]
+	}
+	return t.TableFormat.Padding
+}
+
+// Set Align Type of the header row, Available options: left, right, center
+// If not set, the header uses the same align as the data
+func (t *Tabulate) SetHeaderAlign(align string) {
+	t.HeaderAlign = align
+}
+
+// Get the align type of the header row
+func (t *Tabulate) getHeaderAlign() string {
+	if len(t.HeaderAlign) < 1 {
+		return t.Align
+	}
+	return t.HeaderAlign
+}
+
+// Get the align of a column, the align of its type unless an align is set
+func (t *Tabulate) getColumnAlign(column int, align string) string {
+	column -= t.indexOffset()
+	if len(align) > 0 || column < 0 || column >= len(t.ColumnTypes) {
+		return align
+	}
+	switch t.ColumnTypes[column] {
+	case ColumnInteger, ColumnFloat, ColumnCurrency:
+		return "right"
+	default:
+		return "left"
+	}
+}
+
+// Select the padding function based on the align type, filling the cells with fill
+func (t *Tabulate) getAlignFunc(align string, fill string) func(int, string) string {
+	pad := t.padCenter
+	if len(align) < 1 || align == "right" {
+		pad = t.padLeft
+	} else if align == "left" {
+		pad = t.padRight
+	}
+	return func(width int, str string) string {
+		return pad(width, str, fill)
 	}
 }
 
 // Set how an empty cell will be represented
 func (t *Tabulate) SetEmptyString(empty string) {
-	t.EmptyVar = empty + " "
+	t.EmptyVar = empty
+}
+
+// Set how an empty cell of a column will be represented, instead of the empty string of the table
+func (t *Tabulate) SetColumnEmptyString(index int, empty string) {
+	if t.ColumnEmptyStrings == nil {
+		t.ColumnEmptyStrings = make(map[int]string)
+	}
+	t.ColumnEmptyStrings[index] = empty
+}
+
+// Get how an empty cell of a column is represented
+func (t *Tabulate) getEmptyString(column int) string {
+	if empty, ok := t.ColumnEmptyStrings[column]; ok {
+		return empty
+	}
+	return t.EmptyVar
 }
 
 // Set which lines to hide.
 // Can be:
 // top - Top line of the table,
 // belowheader - Line below the header,
-// bottom - Bottom line of the table
+// betweenrows - Lines between the rows, except the separators of sections,
+// bottomLine - Bottom line of the table
 func (t *Tabulate) SetHideLines(hide []string) {
 	t.HideLines = hide
 }
 
+// Check if a line is hidden, by the table or by its format
+func (t *Tabulate) hidesLine(line string) bool {
+	return inSlice(line, t.HideLines) || inSlice(line, t.TableFormat.HideLines)
+}
+
 // SetWrapStrings toggles fixed length wrapping for all cells.
 func (t *Tabulate) SetWrapStrings(wrap bool) {
 	t.WrapStrings = wrap
@@ -432,62 +2320,533 @@ func (t *Tabulate) SetWrapStrings(wrap bool) {
 // SetAutoSize resizes columns to occupy all terminal width, wrapping automatically.
 func (t *Tabulate) SetAutoSize(autosize bool) {
 	// shrink min padding for small columns
-	MIN_PADDING = 2
+	t.minPadding = 0
+	if autosize {
+		t.minPadding = 2
+	}
 	t.AutoSize = autosize
 }
 
+// Get the minimum padding of the table, shrunk for autosized tables
+func (t *Tabulate) getMinPadding() int {
+	if t.minPadding > 0 {
+		return t.minPadding
+	}
+	return MIN_PADDING
+}
+
+// Set a marker prepended to the first non-empty cell of wrapped continuation rows
+// The marker counts towards the cell width, so the wrapped text still fits the column
+func (t *Tabulate) SetContinuationMarker(marker string) {
+	t.ContinuationMarker = marker
+}
+
+// Set the columns where a cell equal to the one above it is left blank, to show groups of rows
+// A cell is only left blank if the cells of the sparse columns on its left are blank too, like nested groups
+func (t *Tabulate) SetSparseColumns(indices []int) {
+	t.SparseColumns = indices
+}
+
+// Blank the cells of the sparse columns that repeat the cell above them, on copies of the rows
+func (t *Tabulate) sparseColumns(data []*TabulateRow) []*TabulateRow {
+	if len(t.SparseColumns) < 1 {
+		return data
+	}
+	columns := append([]int(nil), t.SparseColumns...)
+	sort.Ints(columns)
+	sparse := make([]*TabulateRow, len(data))
+	for i, row := range data {
+		sparse[i] = row
+		if i < 1 {
+			continue
+		}
+		previous := data[i-1]
+		for _, c := range columns {
+			if c < 0 || c >= len(row.Elements) || c >= len(previous.Elements) || row.Elements[c] != previous.Elements[c] {
+				break
+			}
+			if sparse[i] == row {
+				sparse[i] = row.clone()
+			}
+			sparse[i].Elements[c] = ""
+		}
+	}
+	return sparse
+}
+
+// Set the columns kept verbatim, such as code or logs: their cells are never wrapped,
+// but are split at their newlines into continuation rows, keeping the indentation of every line
+func (t *Tabulate) SetVerbatimColumns(indices []int) {
+	t.VerbatimColumns = indices
+}
+
+// Set the columns to wrap, other columns are never split and keep their natural width
+func (t *Tabulate) SetWrapColumns(indices []int) {
+	t.WrapColumns = indices
+}
+
+// Set the width of a column as a percentage of the terminal width, or of MaxTableWidth if set
+// The cells of the column are wrapped at that width, other columns keep their natural width
+// Returns an error if the percentages of all the columns add up to more than 100
+func (t *Tabulate) SetColumnWidthPercent(index int, pct float64) error {
+	if pct <= 0 {
+		return fmt.Errorf("invalid width percentage %v for column %d", pct, index)
+	}
+	total := pct
+	for i, p := range t.ColumnWidthPercents {
+		if i != index {
+			total += p
+		}
+	}
+	if total > 100 {
+		return fmt.Errorf("column width percentages add up to %v, more than 100", total)
+	}
+	if t.ColumnWidthPercents == nil {
+		t.ColumnWidthPercents = make(map[int]float64)
+	}
+	t.ColumnWidthPercents[index] = pct
+	return nil
+}
+
+// Set if the number of rows is shown below the table, such as "(3 rows)"
+func (t *Tabulate) SetShowRowCount(show bool) {
+	t.ShowRowCount = show
+}
+
+// Set the format of the line showing the number of rows, e.g. "%d results"
+func (t *Tabulate) SetRowCountFormat(format string) {
+	t.RowCountFormat = format
+}
+
+// Set the width available to the table, used instead of the terminal width
+func (t *Tabulate) SetMaxTableWidth(width int) {
+	t.MaxTableWidth = width
+}
+
+// Bound the width of a column, its cells are wrapped at max and it is padded to min
+// A zero max leaves the column without a maximum width
+func (t *Tabulate) SetColumnWidthRange(index int, min, max int) {
+	if max > 0 && max < min {
+		max = min
+	}
+	if t.ColumnWidthRanges == nil {
+		t.ColumnWidthRanges = make(map[int][2]int)
+	}
+	t.ColumnWidthRanges[index] = [2]int{min, max}
+}
+
+// Clamp the widths of the columns to their ranges
+func (t *Tabulate) clampWidths(cols []int) []int {
+	for i, bounds := range t.ColumnWidthRanges {
+		if i < 0 || i >= len(cols) {
+			continue
+		}
+		if cols[i] < bounds[0] {
+			cols[i] = bounds[0]
+		}
+		if bounds[1] > 0 && cols[i] > bounds[1] {
+			cols[i] = bounds[1]
+		}
+	}
+	return cols
+}
+
+// Check if the cells of any column are wrapped in the columns that are not autosized
+func (t *Tabulate) wrapsCells() bool {
+	return t.WrapStrings || len(t.WrapColumns) > 0 || len(t.percentWidths) > 0 || len(t.ColumnWidthRanges) > 0
+}
+
+// Check if the cells of a column are wrapped at the column width
+func (t *Tabulate) wrapsColumn(column int) bool {
+	if _, ok := t.percentWidths[column]; ok {
+		return true
+	}
+	if bounds, ok := t.ColumnWidthRanges[column]; ok && bounds[1] > 0 {
+		return true
+	}
+	if len(t.WrapColumns) > 0 {
+		for _, c := range t.WrapColumns {
+			if c == column {
+				return true
+			}
+		}
+		return false
+	}
+	return t.WrapStrings || t.AutoSize
+}
+
+// Set the maximum number of lines of a wrapped cell, the last line ends with … if the cell is cut
+// Cells have no maximum number of lines by default
+func (t *Tabulate) SetCellMaxLines(n int) {
+	t.CellMaxLines = n
+}
+
+// Set where the cells of a wrapped column are cut when they are too wide: right, left or middle
+// Cells cut on the left keep their end, such as the name of a file, and cells cut in the middle keep both ends.
+// Both are cut to a single line with an ellipsis, right is the default where cells are wrapped
+func (t *Tabulate) SetColumnTruncateSide(index int, side string) {
+	if t.ColumnTruncateSides == nil {
+		t.ColumnTruncateSides = make(map[int]string)
+	}
+	t.ColumnTruncateSides[index] = side
+}
+
+// Set if a hyphen is added where a word longer than its column is cut when wrapped
+// Words are still wrapped at the spaces between them when they fit
+func (t *Tabulate) SetHyphenateWrap(hyphenate bool) {
+	t.HyphenateWrap = hyphenate
+}
+
+// Set if wrapped cells can break between wide characters, such as CJK text that has no spaces
+// Other text is still wrapped at the spaces between words
+func (t *Tabulate) SetCJKWrap(wrap bool) {
+	t.CJKWrap = wrap
+}
+
+// Check if a line break between before and after is allowed between wide characters
+func (t *Tabulate) breaksBetweenWide(before, after string) bool {
+	if !t.CJKWrap || len(before) < 1 || len(after) < 1 {
+		return false
+	}
+	last, _ := utf8.DecodeLastRuneInString(before)
+	next, _ := utf8.DecodeRuneInString(after)
+	return runewidth.RuneWidth(last) == 2 || runewidth.RuneWidth(next) == 2
+}
+
+// Set a hanging indent of n spaces for the continuation lines of wrapped cells
+// The indent counts towards the cell width, so the wrapped text still fits the column
+func (t *Tabulate) SetWrapIndent(n int) {
+	t.WrapIndent = n
+}
+
+// SetASCIIFast measures widths by byte length instead of display width.
+// This is faster on large tables, but misaligns any data that is not pure ASCII
+func (t *Tabulate) SetASCIIFast(fast bool) {
+	t.ASCIIFast = fast
+}
+
 // Sets the maximum size of cell
 // If WrapStrings is set to true, then the string inside
 // the cell will be split up into multiple cell
+// If TruncateInsteadOfWrap is set to true, the string is cut with an ellipsis instead
 func (t *Tabulate) SetMaxCellSize(max int) {
 	t.MaxSize = max
 }
 
+// Set if the cells wider than the maximum cell size are cut to a single line with an ellipsis, instead of wrapped
+// The cells are cut on the side set by SetColumnTruncateSide, right by default
+func (t *Tabulate) SetTruncateInsteadOfWrap(truncate bool) {
+	t.TruncateInsteadOfWrap = truncate
+}
+
+// Cut the cells wider than the maximum cell size, on copies of the rows
+func (t *Tabulate) truncateCells(data []*TabulateRow) []*TabulateRow {
+	if !t.TruncateInsteadOfWrap || t.MaxSize < 1 {
+		return data
+	}
+	truncated := make([]*TabulateRow, len(data))
+	for i, row := range data {
+		truncated[i] = row.clone()
+		for column, e := range row.Elements {
+			if e != "nil" {
+				e = strings.Replace(newlines.Replace(e), "\n", " ", -1)
+				truncated[i].Elements[column] = t.truncateSide(e, t.MaxSize, t.ColumnTruncateSides[column])
+			}
+		}
+	}
+	return truncated
+}
+
+// Check if any cell must be split at its newlines, even without wrapping
+func (t *Tabulate) hasVerbatim() bool {
+	if len(t.VerbatimColumns) > 0 {
+		return true
+	}
+	for _, row := range t.Data {
+		for _, v := range t.getVerbatim(row) {
+			if v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Copy a row, so its elements can be changed without changing the original row
+func (row *TabulateRow) clone() *TabulateRow {
+	copied := *row
+	copied.Elements = append([]string(nil), row.Elements...)
+	return &copied
+}
+
+// Get which cells of a row are verbatim: split at their newlines but never wrapped
+// Cells holding a sub-table and the cells of the verbatim columns are verbatim
+func (t *Tabulate) getVerbatim(row *TabulateRow) []bool {
+	verbatim := make([]bool, len(row.Elements))
+	for i, el := range row.values {
+		if _, ok := el.(*Tabulate); ok && i < len(verbatim) {
+			verbatim[i] = true
+		}
+	}
+	for _, c := range t.VerbatimColumns {
+		if c >= 0 && c < len(verbatim) {
+			verbatim[c] = true
+		}
+	}
+	return verbatim
+}
+
 // If string size is larger than t.MaxSize, then split it to multiple cells (downwards)
-func (t *Tabulate) wrapCellData(cols []int) []*TabulateRow {
+func (t *Tabulate) wrapCellData(ctx context.Context, data []*TabulateRow, cols []int) ([]*TabulateRow, error) {
 	var arr []*TabulateRow
-	next := t.Data[0]
-	for index := 0; index <= len(t.Data); index++ {
+	// wrap copies of the rows, leaving the data of the table untouched
+	next := data[0].clone()
+	continuation := false
+	verbatim := t.getVerbatim(next)
+	// number of lines of the current row
+	lines := 1
+	for index := 0; index <= len(data); index++ {
+		if err := renderCanceled(ctx); err != nil {
+			return nil, err
+		}
 		elements := next.Elements
 		new_elements := make([]string, len(elements))
+		// only the first non-empty cell of a continuation row gets the marker
+		marked := !continuation || len(t.ContinuationMarker) < 1
+		// the cells are cut on the last line allowed by CellMaxLines
+		last := t.CellMaxLines > 0 && lines >= t.CellMaxLines
 
 		for i, e := range elements {
+			// Windows and old Mac line endings split the cell like a newline
+			e = newlines.Replace(e)
+			elements[i] = e
+			// verbatim cells are only split at their newlines
+			if i < len(verbatim) && verbatim[i] {
+				if newlineIndex := strings.Index(e, "\n"); newlineIndex != -1 {
+					elements[i] = e[:newlineIndex]
+					if !last {
+						new_elements[i] = e[newlineIndex+1:]
+						next.Continuous = true
+					}
+				}
+				continue
+			}
+			if !t.wrapsColumn(i) {
+				continue
+			}
 			maxColWidth := t.MaxSize
 			if t.AutoSize {
 				maxColWidth = cols[i]
 			}
+			if w, ok := t.percentWidths[i]; ok {
+				maxColWidth = w
+			} else if bounds, ok := t.ColumnWidthRanges[i]; ok && !t.AutoSize {
+				maxColWidth = bounds[1]
+			}
+			// cells truncated on the left or in the middle are cut instead of wrapped
+			if side := t.ColumnTruncateSides[i]; side == "left" || side == "middle" {
+				elements[i] = t.truncateSide(strings.Replace(e, "\n", " ", -1), maxColWidth, side)
+				continue
+			}
+			// indent the continuation fragments of a cell
+			indent := ""
+			if continuation && len(e) > 0 && t.WrapIndent > 0 && t.WrapIndent < maxColWidth {
+				indent = strings.Repeat(" ", t.WrapIndent)
+				maxColWidth -= t.WrapIndent
+			}
+			marker := ""
+			if !marked && len(e) > 0 {
+				marked = true
+				if runewidth.StringWidth(t.ContinuationMarker) < maxColWidth {
+					marker = t.ContinuationMarker
+					maxColWidth -= runewidth.StringWidth(marker)
+				}
+			}
 			// if newline found before maxColWidth, truncate there instead
+			// each paragraph is then wrapped on its own in the continuation rows
 			newlineIndex := strings.Index(e, "\n")
-			if newlineIndex != -1 && newlineIndex < maxColWidth {
+			if newlineIndex != -1 && t.stringWidth(e[:newlineIndex]) <= maxColWidth {
 				elements[i] = e[:newlineIndex]
 				new_elements[i] = e[len(elements[i])+1:]
 				next.Continuous = true
-			} else if runewidth.StringWidth(e) > maxColWidth {
-				elements[i] = runewidth.Truncate(e, maxColWidth, "")
+			} else if t.stringWidth(e) > maxColWidth {
+				elements[i] = t.truncate(e, maxColWidth)
+				hyphen := ""
 				// if last letter is inside a word, back up until the start of the last word
-				if elements[i][len(elements[i])-1:] != " " {
+				// wide characters can be broken anywhere if CJKWrap is set
+				if elements[i][len(elements[i])-1:] != " " && !t.breaksBetweenWide(elements[i], e[len(elements[i]):]) {
 					lastWordStart := strings.LastIndex(elements[i], " ")
 					if lastWordStart != -1 {
 						elements[i] = elements[i][:lastWordStart+1]
+					} else if t.HyphenateWrap && maxColWidth > 1 && !strings.HasPrefix(e[len(elements[i]):], " ") {
+						// a word longer than the column is cut one character earlier, to fit a hyphen
+						elements[i] = t.truncate(e, maxColWidth-1)
+						if !strings.HasSuffix(elements[i], "-") {
+							hyphen = "-"
+						}
 					}
 				}
-				new_elements[i] = e[len(elements[i]):]
+				// the continuation starts at the next word, without the spaces separating them
+				new_elements[i] = strings.TrimLeft(e[len(elements[i]):], " ")
+				elements[i] += hyphen
 				next.Continuous = true
 			}
+			// drop the rest of the cell, and show it is truncated
+			if last && len(new_elements[i]) > 0 {
+				new_elements[i] = ""
+				elements[i] = t.truncate(strings.TrimRight(elements[i], " "), maxColWidth-1) + "…"
+			}
+			elements[i] = marker + indent + elements[i]
+		}
+		if last {
+			next.Continuous = false
 		}
 		if next.Continuous {
 			arr = append(arr, next)
-			next = &TabulateRow{Elements: new_elements}
+			next = &TabulateRow{Elements: new_elements, Separator: next.Separator, changed: next.changed, color: next.color}
+			continuation = true
+			lines++
 			index--
-		} else if index+1 < len(t.Data) {
+		} else if index+1 < len(data) {
 			arr = append(arr, next)
-			next = t.Data[index+1]
-		} else if index >= len(t.Data) {
+			next = data[index+1].clone()
+			continuation = false
+			lines = 1
+			verbatim = t.getVerbatim(next)
+		} else if index >= len(data) {
 			arr = append(arr, next)
 		}
 
 	}
-	return arr
+	return arr, nil
+}
+
+// Set the function ordering the cells of a column when sorting, such as versions or the values of an enum
+// Columns without a comparator are sorted by number when both cells are numbers, and as strings otherwise
+func (t *Tabulate) SetColumnComparator(index int, less func(a, b string) bool) {
+	if t.ColumnComparators == nil {
+		t.ColumnComparators = make(map[int]func(a, b string) bool)
+	}
+	t.ColumnComparators[index] = less
+}
+
+// Sort the rows of the data by the keys, the first key first. The sort is stable
+// Numbers are compared by value and text lexicographically, or by the comparator of the column,
+// numbers come before text and empty cells are always last.
+// Wrapped rows stay with their logical row, and the first row stays on top if it is used as headers
+func (t *Tabulate) SortByColumns(keys []SortKey) {
+	data := t.Data
+	var first []*TabulateRow
+	if len(t.Headers) < 1 && len(data) > 0 {
+		first, data = data[:1], data[1:]
+	}
+	rows := logicalRows(data)
+	sort.Stable(sortedRows{rows: rows, keys: keys, less: t.ColumnComparators, number: t.parseNumber})
+
+	sorted := append([]*TabulateRow(nil), first...)
+	for _, row := range rows {
+		sorted = append(sorted, row...)
+	}
+	t.Data = sorted
+	t.sortKeys = append([]SortKey(nil), keys...)
+}
+
+// Set if the headers of the columns the rows were last sorted by end with an arrow, ▲ ascending or ▼ descending
+func (t *Tabulate) SetShowSortIndicators(show bool) {
+	t.ShowSortIndicators = show
+}
+
+// Get the sort indicator of the header of a column, empty if the rows are not sorted by the column
+func (t *Tabulate) getSortIndicator(column int) string {
+	if !t.ShowSortIndicators {
+		return ""
+	}
+	for _, key := range t.sortKeys {
+		if key.Column != column {
+			continue
+		}
+		if key.Descending {
+			return " ▼"
+		}
+		return " ▲"
+	}
+	return ""
+}
+
+// Logical rows sorted by keys
+type sortedRows struct {
+	rows [][]*TabulateRow
+	keys []SortKey
+	less map[int]func(a, b string) bool
+	// parse a cell as a number
+	number func(string) (float64, bool)
+}
+
+func (s sortedRows) Len() int      { return len(s.rows) }
+func (s sortedRows) Swap(i, j int) { s.rows[i], s.rows[j] = s.rows[j], s.rows[i] }
+
+func (s sortedRows) Less(i, j int) bool {
+	for _, key := range s.keys {
+		a, b := getCell(s.rows[i][0], key.Column), getCell(s.rows[j][0], key.Column)
+		if a == b {
+			continue
+		}
+		// empty cells are last, whatever the order
+		if a == "" || b == "" {
+			return b == ""
+		}
+		// the comparator of the column replaces the default order
+		if less, ok := s.less[key.Column]; ok && less != nil {
+			if less(a, b) {
+				return !key.Descending
+			}
+			if less(b, a) {
+				return key.Descending
+			}
+			continue
+		}
+		fa, okA := s.number(a)
+		fb, okB := s.number(b)
+		if okA && okB {
+			if fa == fb {
+				continue
+			}
+			return (fa < fb) != key.Descending
+		}
+		// numbers come before text, so the order stays the same whatever the order of the rows
+		if okA != okB {
+			return okA != key.Descending
+		}
+		return (a < b) != key.Descending
+	}
+	return false
+}
+
+// Get a cell of a row for sorting, empty if it is nil or missing
+func getCell(row *TabulateRow, column int) string {
+	if column < 0 || column >= len(row.Elements) || row.Elements[column] == "nil" {
+		return ""
+	}
+	return strings.TrimSpace(row.Elements[column])
+}
+
+// Default configuration of a Tabulate Object
+func defaultTabulate() Tabulate {
+	return Tabulate{FloatFormat: 'f', MaxSize: 30, DateFormat: time.RFC3339}
+}
+
+// Reset restores the configuration to the defaults of Create and clears Data and Headers,
+// so the same Tabulate Object can be reused.
+func (t *Tabulate) Reset() {
+	*t = defaultTabulate()
+}
+
+// ResetSettings restores the configuration like Reset, but keeps Data and Headers.
+func (t *Tabulate) ResetSettings() {
+	data, headers := t.Data, t.Headers
+	t.Reset()
+	t.Data, t.Headers = data, headers
+	t.normalize()
 }
 
 // Create a new Tabulate Object
@@ -495,7 +2854,8 @@ func (t *Tabulate) wrapCellData(cols []int) []*TabulateRow {
 // 2D Bool Array, 2D Float64 Array, 2D interface{} Array,
 // Map map[string]string, Map map[string]interface{},
 func Create(data interface{}) *Tabulate {
-	t := &Tabulate{FloatFormat: 'f', MaxSize: 30}
+	defaults := defaultTabulate()
+	t := &defaults
 
 	switch v := data.(type) {
 	case [][]string:
@@ -509,20 +2869,134 @@ func Create(data interface{}) *Tabulate {
 	case [][]bool:
 		t.Data = createFromBool(data.([][]bool))
 	case [][]float64:
-		t.Data = createFromFloat64(data.([][]float64), t.FloatFormat)
+		t.Data = createFromFloat64(data.([][]float64))
 	case [][]interface{}:
-		t.Data = createFromMixed(data.([][]interface{}), t.FloatFormat)
+		t.Data = createFromMixed(data.([][]interface{}))
 	case []string:
 		t.Data = createFromString([][]string{data.([]string)})
 	case []interface{}:
-		t.Data = createFromMixed([][]interface{}{data.([]interface{})}, t.FloatFormat)
+		t.Data = createFromMixed([][]interface{}{data.([]interface{})})
 	case map[string][]interface{}:
-		t.Headers, t.Data = createFromMapMixed(data.(map[string][]interface{}), t.FloatFormat)
+		t.Headers, t.Data = createFromMapMixed(data.(map[string][]interface{}))
 	case map[string][]string:
 		t.Headers, t.Data = createFromMapString(data.(map[string][]string))
 	default:
 		fmt.Println(v)
 	}
+	t.normalize()
 
 	return t
 }
+
+// Insert a row of values in the data at index, the next rows are shifted down
+// The values are formatted like the cells of a 2D interface{} Array, an index out of range inserts at the start or the end
+func (t *Tabulate) InsertRow(index int, cells ...interface{}) {
+	row := &TabulateRow{values: cells, Elements: make([]string, len(cells))}
+	for i, el := range cells {
+		row.Elements[i] = t.formatValue(el)
+	}
+	if index < 0 {
+		index = 0
+	} else if index > len(t.Data) {
+		index = len(t.Data)
+	}
+	t.Data = append(t.Data[:index], append([]*TabulateRow{row}, t.Data[index:]...)...)
+}
+
+// Render headers and rows in one call, with the default settings
+// The table is rendered in the grid format if format is empty
+func Quick(headers []string, data [][]string, format string) string {
+	if len(format) < 1 {
+		format = "grid"
+	}
+	t := Create(data)
+	t.SetHeaders(headers)
+	return t.Render(format)
+}
+
+// Get a deep copy of the table, its data and its settings
+// The copy can be changed and rendered without affecting the table, e.g. to render variants in parallel
+func (t *Tabulate) Clone() *Tabulate {
+	c := *t
+	c.Data = make([]*TabulateRow, len(t.Data))
+	for i, row := range t.Data {
+		c.Data[i] = row.clone()
+		c.Data[i].values = append([]interface{}(nil), row.values...)
+		c.Data[i].changed = append([]bool(nil), row.changed...)
+	}
+	c.Headers = append([]string(nil), t.Headers...)
+	c.TableFormat.HideLines = append([]string(nil), t.TableFormat.HideLines...)
+	c.HideLines = append([]string(nil), t.HideLines...)
+	c.WrapColumns = append([]int(nil), t.WrapColumns...)
+	c.VerbatimColumns = append([]int(nil), t.VerbatimColumns...)
+	c.SparseColumns = append([]int(nil), t.SparseColumns...)
+	c.ColumnPadding = append([]int(nil), t.ColumnPadding...)
+	c.ColumnSeparators = append([]string(nil), t.ColumnSeparators...)
+	c.GrandTotalColumns = append([]int(nil), t.GrandTotalColumns...)
+	c.HeatmapColumns = append([]int(nil), t.HeatmapColumns...)
+	c.ColumnTypes = append([]ColumnType(nil), t.ColumnTypes...)
+	c.Notes = append([]Note(nil), t.Notes...)
+	if t.Previous != nil {
+		c.Previous = make([][]string, len(t.Previous))
+		for i, row := range t.Previous {
+			c.Previous[i] = append([]string(nil), row...)
+		}
+	}
+
+	c.ColumnEmptyStrings = copyStrings(t.ColumnEmptyStrings)
+	c.ColumnTruncateSides = copyStrings(t.ColumnTruncateSides)
+	c.AutoFooter = copyStrings(t.AutoFooter)
+	c.CurrencySymbols = copyStrings(t.CurrencySymbols)
+	c.ColumnPrefixes = copyStrings(t.ColumnPrefixes)
+	c.ColumnSuffixes = copyStrings(t.ColumnSuffixes)
+	c.HeaderIcons = copyStrings(t.HeaderIcons)
+	if t.ColumnWidthPercents != nil {
+		c.ColumnWidthPercents = make(map[int]float64)
+		for k, v := range t.ColumnWidthPercents {
+			c.ColumnWidthPercents[k] = v
+		}
+	}
+	if t.ColumnWidthRanges != nil {
+		c.ColumnWidthRanges = make(map[int][2]int)
+		for k, v := range t.ColumnWidthRanges {
+			c.ColumnWidthRanges[k] = v
+		}
+	}
+	if t.AlignByRegex != nil {
+		c.AlignByRegex = make(map[int]RegexAlign)
+		for k, v := range t.AlignByRegex {
+			c.AlignByRegex[k] = v
+		}
+	}
+	if t.AlignChars != nil {
+		c.AlignChars = make(map[int]rune)
+		for k, v := range t.AlignChars {
+			c.AlignChars[k] = v
+		}
+	}
+	if t.ColumnFormatters != nil {
+		c.ColumnFormatters = make(map[int]func(string) string)
+		for k, v := range t.ColumnFormatters {
+			c.ColumnFormatters[k] = v
+		}
+	}
+	if t.ColumnComparators != nil {
+		c.ColumnComparators = make(map[int]func(a, b string) bool)
+		for k, v := range t.ColumnComparators {
+			c.ColumnComparators[k] = v
+		}
+	}
+	return &c
+}
+
+// Copy a map of strings indexed by column, nil stays nil
+func copyStrings(m map[int]string) map[int]string {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[int]string, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
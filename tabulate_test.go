@@ -1,9 +1,20 @@
 package gotabulate
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
 	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/mattn/go-runewidth"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -37,6 +48,14 @@ func TestPads(t *testing.T) {
 
 }
 
+func TestHeaderAlign(t *testing.T) {
+	tabulate := Create([][]string{STRING_ARRAY, STRING_ARRAY})
+	tabulate.SetHeaders(HEADERS)
+	tabulate.SetAlign("left")
+	tabulate.SetHeaderAlign("center")
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_header_align"))
+}
+
 func TestSetHeaders(t *testing.T) {
 	tabulate := Create([][]string{STRING_ARRAY})
 	tabulate.SetHeaders(HEADERS)
@@ -55,6 +74,216 @@ func TestEmptyString(t *testing.T) {
 	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_empty_element"))
 }
 
+func TestEmptyStringPadding(t *testing.T) {
+	tabulate := Create([][]string{{"a", "b"}, {"c"}})
+	tabulate.SetHeaders([]string{"h1", "h2"})
+	tabulate.SetEmptyString("-")
+	tabulate.SetAlign("left")
+	assert.Equal(t, "-", tabulate.EmptyVar)
+	assert.Contains(t, tabulate.Render("grid"), "\n| c     | -     |\n")
+}
+
+func TestNaNInfFormat(t *testing.T) {
+	tabulate := Create([][]float64{{1.5, math.NaN(), math.Inf(1), math.Inf(-1)}})
+	tabulate.SetHeaders([]string{"value", "nan", "inf", "-inf"})
+	tabulate.SetNaNFormat("-")
+	tabulate.SetInfFormat("∞")
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_nan_inf"))
+}
+
+func TestFixedWidth(t *testing.T) {
+	tabulate := Create([][]string{{"a", "b", "c"}, {"a"}})
+	tabulate.SetHeaders([]string{"h1", "h2", "h3"})
+	tabulate.SetEmptyString("Not available")
+	tabulate.SetAlign("left")
+	// every line is padded to the width of the table, the empty strings included
+	for _, line := range strings.Split(tabulate.Render("plain"), "\n") {
+		if len(line) > 0 {
+			assert.Equal(t, 47, runewidth.StringWidth(line))
+		}
+	}
+}
+
+func TestColumnPadding(t *testing.T) {
+	tabulate := Create([][]string{STRING_ARRAY, STRING_ARRAY})
+	tabulate.SetHeaders(HEADERS)
+	tabulate.SetColumnPadding([]int{0, 2})
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_column_padding"))
+}
+
+func TestEmptyGlyphsFormat(t *testing.T) {
+	tabulate := Create([][]string{STRING_ARRAY, STRING_ARRAY})
+	tabulate.SetHeaders(HEADERS)
+	tabulate.SetHideLines([]string{"top", "bottomLine"})
+	tabulate.TableFormat = TableFormat{
+		LineBelowHeader: Line{"", "-", " ", ""},
+		Padding:         1,
+	}
+	rendered := tabulate.Render()
+	for _, line := range strings.Split(rendered, "\n") {
+		if len(line) > 0 {
+			assert.Equal(t, 73, runewidth.StringWidth(line))
+		}
+	}
+	assert.Equal(t, rendered, readTable("_tests/test_empty_glyphs"))
+}
+
+func TestBoolFormat(t *testing.T) {
+	tabulate := Create([][]bool{{true, false}, {false, true}})
+	tabulate.SetHeaders([]string{"enabled", "ready"})
+	tabulate.SetBoolFormat("✓", "✗")
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_bool_format"))
+}
+
+func TestNegativeColor(t *testing.T) {
+	tabulate := Create([][]interface{}{{"a", 10, -2.5}, {"b", -3, 1.25}})
+	tabulate.SetHeaders([]string{"name", "int", "float"})
+	tabulate.SetNegativeColor("\033[31m", "\033[0m")
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_negative_color"))
+}
+
+func TestDateFormat(t *testing.T) {
+	date := time.Date(2016, time.June, 5, 14, 30, 0, 0, time.UTC)
+	tabulate := Create([][]interface{}{{"deploy", date, 90 * time.Second}})
+	tabulate.SetHeaders([]string{"event", "date", "duration"})
+	tabulate.SetDateFormat("2006-01-02 15:04")
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_date_format"))
+}
+
+func TestReset(t *testing.T) {
+	tabulate := Create([][]float64{FLOAT_ARRAY, FLOAT_ARRAY, FLOAT_ARRAY})
+	tabulate.SetHeaders(HEADERS)
+	tabulate.SetFloatFormat('e')
+	tabulate.SetAlign("left")
+	tabulate.SetHideLines([]string{"top"})
+	tabulate.ResetSettings()
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/table_float"))
+
+	// the padding shrunk by SetAutoSize is restored, without changing the other tables
+	other := Create([][]float64{FLOAT_ARRAY, FLOAT_ARRAY, FLOAT_ARRAY})
+	other.SetHeaders(HEADERS)
+	other.SetAutoSize(true)
+	other.SetMaxTableWidth(200)
+	rendered := other.Render("grid")
+	tabulate.SetAutoSize(true)
+	tabulate.ResetSettings()
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/table_float"))
+	assert.Equal(t, rendered, other.Render("grid"))
+
+	tabulate.Reset()
+	assert.Empty(t, tabulate.Data)
+	assert.Empty(t, tabulate.Headers)
+	assert.Equal(t, Tabulate{FloatFormat: 'f', MaxSize: 30, DateFormat: time.RFC3339}, *tabulate)
+}
+
+func TestRenderWithSeparator(t *testing.T) {
+	tabulate := Create([][]string{{"a|b", "50%", "c"}, {"d", "e", "f"}})
+	tabulate.SetHeaders([]string{"first", "second", "third"})
+	assert.Equal(t, tabulate.RenderWithSeparator("|"), readTable("_tests/test_render_separator"))
+	assert.Equal(t, []string{"a|b", "50%", "c"}, tabulate.Data[0].Elements)
+
+	// The formatted cells and the empty strings are escaped too
+	tabulate = Create([][]interface{}{{"a", 1, nil}})
+	tabulate.SetHeaders([]string{"x", "y", "z"})
+	tabulate.SetColumnPrefix(1, "|")
+	tabulate.SetEmptyString(";")
+	assert.Equal(t, "    x |       y |    z \n    a |    %7C1 |    ; \n", tabulate.RenderWithSeparator("|"))
+	assert.Equal(t, "    x ;     y ;      z \n    a ;    |1 ;    %3B \n", tabulate.RenderWithSeparator(";"))
+	assert.Equal(t, ";", tabulate.EmptyVar)
+
+	// The rows keep their values, index and colors
+	tabulate = Create([][]interface{}{{"a", time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)}})
+	tabulate.SetHeaders([]string{"x", "y"})
+	tabulate.SetColumnTypes([]ColumnType{ColumnText, ColumnDate})
+	tabulate.SetDateFormat("2006-01-02")
+	tabulate.SetIndexColumn("#")
+	tabulate.SetRowColorFunc(func(cells []string) (string, string, bool) { return "<", ">", true })
+	assert.Equal(t, "    # | x    | y             \n<    1 | a    | 2020-01-02    >\n", tabulate.RenderWithSeparator("|"))
+}
+
+func TestRowSeparator(t *testing.T) {
+	tabulate := Create([][]string{{"a", "1"}, {"b", "2"}, {"c", "3"}, {"d", "4"}})
+	tabulate.SetHeaders([]string{"name", "value"})
+	tabulate.Data[1].Separator = true
+	assert.Equal(t, tabulate.Render("simple"), readTable("_tests/test_row_separator"))
+}
+
+func TestRenderNDJSON(t *testing.T) {
+	tabulate := Create([][]interface{}{{"Lorem ipsum dolor sit amet", 1, nil}, {"b", 2, "x"}})
+	tabulate.SetHeaders([]string{"name", "name", ""})
+	tabulate.SetMaxCellSize(12)
+	tabulate.SetWrapStrings(true)
+	tabulate.Render("grid")
+
+	var buffer bytes.Buffer
+	assert.Nil(t, tabulate.RenderNDJSON(&buffer))
+	assert.Equal(t, `{"name":"Lorem ipsum dolor sit amet","name_2":"1","column_3":null}
+{"name":"b","name_2":"2","column_3":"x"}
+`, buffer.String())
+}
+
+func TestASCIIFast(t *testing.T) {
+	tabulate := Create([][]float64{FLOAT_ARRAY, FLOAT_ARRAY, FLOAT_ARRAY})
+	tabulate.SetHeaders(HEADERS)
+	tabulate.SetASCIIFast(true)
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/table_float"))
+}
+
+func TestColumnSeparators(t *testing.T) {
+	tabulate := Create([][]string{{"a", "1", "2"}, {"b", "3", "4"}})
+	tabulate.SetHeaders([]string{"key", "first", "second"})
+	tabulate.SetColumnSeparators([]string{"║"})
+	assert.Equal(t, tabulate.Render("grid")+tabulate.Render("simple"), readTable("_tests/test_column_separators"))
+}
+
+func TestRenderColumnar(t *testing.T) {
+	tabulate := Create([][]string{STRING_ARRAY, EMPTY_ARRAY})
+	tabulate.SetHeaders(HEADERS)
+	tabulate.SetEmptyString("None")
+	var buffer bytes.Buffer
+	assert.Nil(t, tabulate.RenderColumnar(&buffer, 2))
+	assert.Equal(t, buffer.String(), readTable("_tests/test_columnar"))
+
+	tabulate = Create([][]interface{}{{"a", nil}})
+	tabulate.SetHeaders([]string{"x", "y"})
+	tabulate.SetColumnEmptyString(1, "N/A")
+	buffer.Reset()
+	assert.Nil(t, tabulate.RenderColumnar(&buffer, 2))
+	assert.Equal(t, "x  y\na  N/A\n", buffer.String())
+}
+
+func TestAutoFooter(t *testing.T) {
+	tabulate := Create([][]interface{}{{"apples", 3, 1.5}, {"pears", 5, 2.25}, {"plums", "n/a", 4.0}})
+	tabulate.SetHeaders([]string{"fruit", "count", "price"})
+	tabulate.SetAutoFooter(map[int]string{-1: "sum", 1: "sum", 2: "avg", 3: "max"})
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_auto_footer"))
+}
+
+func TestZeroColumns(t *testing.T) {
+	_, err := Create([][]string{}).SafeRender("grid")
+	assert.EqualError(t, err, "No Data specified")
+
+	_, err = Create([][]string{{}}).SafeRender("grid")
+	assert.EqualError(t, err, "No Data specified")
+
+	tabulate := Create([][]string{{}, {}})
+	tabulate.SetHeaders([]string{})
+	_, err = tabulate.SafeRender("grid")
+	assert.EqualError(t, err, "No columns specified")
+	assert.Panics(t, func() { tabulate.Render("grid") })
+
+	rendered, err := Create([][]string{STRING_ARRAY, STRING_ARRAY}).SafeRender("grid")
+	assert.Nil(t, err)
+	assert.Equal(t, rendered, readTable("_tests/test_first_row"))
+}
+
+func TestLocale(t *testing.T) {
+	tabulate := Create([][]interface{}{{"a", 1234567, 1234.56}, {"b", -1000, -0.5}, {"c", 12, math.NaN()}})
+	tabulate.SetHeaders([]string{"name", "int", "float"})
+	tabulate.SetLocale(',', '.')
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_locale"))
+}
+
 func TestMaxColWidth(t *testing.T) {
 	// TODO
 }
@@ -182,6 +411,1092 @@ func TestWrapCellsSimple(t *testing.T) {
 	assert.EqualValues(t, readTable("_tests/test_string_wrap_simple"), tabulate.Render("simple"))
 }
 
+func TestWrapParagraphs(t *testing.T) {
+	tabulate := Create([][]string{{"short\n" + strings.Repeat("x ", 50), "b"}, {"exactly sixteen!\n" + strings.Repeat("y ", 10), "c"}})
+	tabulate.SetHeaders([]string{"text", "other"})
+	tabulate.SetMaxCellSize(16)
+	tabulate.SetWrapStrings(true)
+	tabulate.SetAlign("left")
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_string_wrap_paragraphs"))
+}
+
+func TestWrapBoundary(t *testing.T) {
+	tabulate := Create([][]string{{"hello world", "x"}, {"abcd efgh", "y"}, {"ab    cd", "z"}})
+	tabulate.SetHeaders([]string{"text", "other"})
+	tabulate.SetMaxCellSize(5)
+	tabulate.SetWrapStrings(true)
+	tabulate.SetAlign("left")
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_string_wrap_boundary"))
+}
+
+func TestRenderKeepsInput(t *testing.T) {
+	row := []string{"Lorem ipsum dolor sit amet", "short"}
+	input := [][]string{row, STRING_ARRAY[:2]}
+	tabulate := Create(input)
+	tabulate.SetHeaders([]string{"text", "other"})
+	tabulate.SetMaxCellSize(12)
+	tabulate.SetWrapStrings(true)
+	first := tabulate.Render("grid")
+
+	assert.Equal(t, []string{"Lorem ipsum dolor sit amet", "short"}, row)
+	assert.Equal(t, []string{"test string", "test string 2"}, input[1])
+	assert.Equal(t, 2, len(tabulate.Data))
+	assert.False(t, tabulate.Data[0].Continuous)
+	assert.Equal(t, first, tabulate.Render("grid"))
+}
+
+func TestWrapColumns(t *testing.T) {
+	tabulate := Create([][]string{{"2024-01-01-000001", "A rather long description that wraps"}, {"2024-01-02-000002", "Short"}})
+	tabulate.SetHeaders([]string{"id", "description"})
+	tabulate.SetMaxCellSize(12)
+	tabulate.SetWrapColumns([]int{1})
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_wrap_columns"))
+}
+
+func TestNotes(t *testing.T) {
+	tabulate := Create([][]string{{"Alpha", "12.5"}, {"Beta", "7"}})
+	tabulate.SetHeaders([]string{"name", "value"})
+	tabulate.AddNote(0, 1, "Estimated")
+	tabulate.AddNote(1, 0, "Renamed in 2024")
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_notes"))
+	assert.Equal(t, "12.5", tabulate.Data[0].Elements[1])
+}
+
+func TestFillChar(t *testing.T) {
+	tabulate := Create([][]string{{"Name", "John"}, {"Occupation", "Engineer"}})
+	tabulate.SetHeaders([]string{"field", "value"})
+	tabulate.SetAlign("left")
+	tabulate.SetFillChar('.')
+	assert.Equal(t, tabulate.Render("plain"), readTable("_tests/test_fill_char"))
+
+	// wide characters would break the alignment
+	tabulate.SetFillChar('全')
+	assert.Equal(t, '.', tabulate.FillChar)
+}
+
+func TestColumnTypes(t *testing.T) {
+	tabulate := Create([][]interface{}{{"Apple", "3", 1.5, "true", "2024-03-01T10:00:00Z", 1234.5}, {"Banana", 12.0, "2.25", false, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), "7"}})
+	tabulate.SetHeaders([]string{"name", "count", "weight", "ripe", "picked", "price"})
+	tabulate.SetColumnTypes([]ColumnType{ColumnText, ColumnInteger, ColumnFloat, ColumnBool, ColumnDate, ColumnCurrency})
+	tabulate.SetDateFormat("2006-01-02")
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_column_types"))
+
+	// an explicit align wins over the align of the types
+	tabulate.SetAlign("right")
+	assert.Contains(t, tabulate.Render("grid"), "|     Apple |")
+
+	// integers with a fraction are not rounded
+	fraction := Create([][]interface{}{{2.5}, {"3.0"}})
+	fraction.SetHeaders([]string{"n"})
+	fraction.SetColumnTypes([]ColumnType{ColumnInteger})
+	assert.Equal(t, "\n      n \n\n    2.5 \n\n      3 \n\n", fraction.Render("plain"))
+}
+
+func TestRaggedFill(t *testing.T) {
+	for _, side := range []string{"left", "right"} {
+		tabulate := Create([][]string{{"a", "b", "c", "d"}, {"long e", "f"}})
+		tabulate.SetHeaders([]string{"q1", "q2", "q3", "q4"})
+		tabulate.SetRaggedFill(side)
+		assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_ragged_fill_"+side))
+	}
+}
+
+func TestTabWidth(t *testing.T) {
+	tabulate := Create([][]string{{"a\tb", "abc\td"}})
+	tabulate.SetHeaders([]string{"first", "second"})
+	tabulate.SetAlign("left")
+	assert.Contains(t, tabulate.Render("simple"), "a\tb")
+
+	tabulate.SetTabWidth(4)
+	assert.Contains(t, tabulate.Render("simple"), " a   b       abc d     \n")
+	assert.Equal(t, "a\tb", tabulate.Data[0].Elements[0])
+}
+
+func TestRenderTrimmed(t *testing.T) {
+	tabulate := Create([][]string{{"a", "bb"}, {"ccc", ""}})
+	tabulate.SetHeaders([]string{"x", "y"})
+	tabulate.SetAlign("left")
+	for _, format := range []string{"grid", "simple", "plain"} {
+		trimmed := tabulate.RenderTrimmed(format)
+		lines := strings.Split(tabulate.Render(format), "\n")
+		for i, line := range strings.Split(trimmed, "\n") {
+			assert.Equal(t, strings.TrimRight(lines[i], " "), line)
+		}
+	}
+	assert.Contains(t, tabulate.RenderTrimmed("simple"), "\n a         bb\n\n ccc\n")
+}
+
+func TestHeaderTransform(t *testing.T) {
+	tabulate := Create([][]string{{"a", "b"}})
+	tabulate.SetHeaders([]string{"first name", "LAST name"})
+	tabulate.SetAlign("left")
+	tabulate.UpperHeaders()
+	assert.Contains(t, tabulate.Render("simple"), "\n FIRST NAME       LAST NAME    \n")
+
+	tabulate.TitleHeaders()
+	assert.Contains(t, tabulate.Render("simple"), "\n First Name       Last Name    \n")
+
+	tabulate.SetHeaderTransform(func(h string) string { return "[" + h + "]" })
+	assert.Contains(t, tabulate.Render("simple"), "\n [first name]       [LAST name]    \n")
+	assert.Equal(t, []string{"first name", "LAST name"}, tabulate.Headers)
+}
+
+func TestTitleCase(t *testing.T) {
+	assert.Equal(t, "First Name", titleCase("first NAME"))
+	assert.Equal(t, "Don't Stop", titleCase("don't stop"))
+	assert.Equal(t, "Élan-Vital Über", titleCase("éLAN-vital über"))
+	assert.Equal(t, "ǅemal", titleCase("ǆemal"))
+}
+
+func TestRenderStacked(t *testing.T) {
+	first := Create([][]string{{"apples", "3"}, {"pears", "12"}})
+	first.SetHeaders([]string{"fruit", "count"})
+	second := Create([][]string{{"carrots", "150"}})
+	second.SetHeaders([]string{"vegetable", "n"})
+	assert.Equal(t, RenderStacked([]*Tabulate{first, second}, "border"), readTable("_tests/test_render_stacked"))
+}
+
+func TestStripZeroWidth(t *testing.T) {
+	family := "\U0001F468\u200d\U0001F469\u200d\U0001F467"
+	tabulate := Create([][]string{{"\ufeffzero\u200bwidth", "soft\u00adhyphen\u200d", family}})
+	tabulate.SetHeaders([]string{"a", "b", "c"})
+	tabulate.SetStripZeroWidth(true)
+	rendered := tabulate.Render("simple")
+	assert.Contains(t, rendered, " zerowidth ")
+	assert.Contains(t, rendered, " softhyphen ")
+	assert.Contains(t, rendered, " "+family+" ")
+	assert.Equal(t, "\ufeffzero\u200bwidth", tabulate.Data[0].Elements[0])
+}
+
+func TestMaxColumns(t *testing.T) {
+	tabulate := Create([][]string{{"1", "2", "3", "4", "5"}, {"6", "7", "8", "9", "10"}})
+	tabulate.SetHeaders([]string{"a", "b", "c", "d", "e"})
+	tabulate.SetMaxColumns(3)
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_max_columns"))
+
+	tabulate.SetMaxColumns(5)
+	assert.NotContains(t, tabulate.Render("grid"), "…")
+}
+
+func TestSeparatorEvery(t *testing.T) {
+	tabulate := Create([][]string{{"1", "a"}, {"2", "b"}, {"3", "a long value"}, {"4", "d"}, {"5", "e"}})
+	tabulate.SetHeaders([]string{"n", "value"})
+	tabulate.SetMaxCellSize(6)
+	tabulate.SetWrapStrings(true)
+	tabulate.SetSeparatorEvery(2)
+	assert.Equal(t, tabulate.Render("simple"), readTable("_tests/test_separator_every"))
+
+	// formats with lines between rows are unchanged
+	grid := tabulate.Render("grid")
+	tabulate.SetSeparatorEvery(0)
+	assert.Equal(t, tabulate.Render("grid"), grid)
+}
+
+func TestEscapeControl(t *testing.T) {
+	tabulate := Create([][]string{{"bell\x07", "nul\x00\x7f", "c1\u0085"}})
+	tabulate.SetHeaders([]string{"a", "b", "c"})
+	tabulate.SetEscapeControl(true)
+	rendered := tabulate.Render("simple")
+	assert.Contains(t, rendered, " bell^G ")
+	assert.Contains(t, rendered, " nul^@^? ")
+	assert.Contains(t, rendered, " c1\\x85 ")
+	assert.Equal(t, "bell\x07", tabulate.Data[0].Elements[0])
+}
+
+func TestColumnFormatter(t *testing.T) {
+	tabulate := Create([][]interface{}{{"ab", 12.5}, {"cd", 7.0}, {"ef", nil}})
+	tabulate.SetHeaders([]string{"code", "price"})
+	tabulate.SetColumnFormatter(0, strings.ToUpper)
+	tabulate.SetColumnFormatter(1, func(s string) string { return "$" + s })
+	rendered := tabulate.Render("simple")
+	assert.Contains(t, rendered, "\n      AB       $12.5 \n")
+	assert.NotContains(t, rendered, "$nil")
+	assert.Contains(t, rendered, "\n    code       price \n")
+}
+
+func TestUnknownFormat(t *testing.T) {
+	tabulate := Create([][]string{STRING_ARRAY, STRING_ARRAY})
+	_, err := tabulate.SafeRender("grdi")
+	assert.EqualError(t, err, `unknown format "grdi", available: [ascii_grid border grid headeronly plain simple]`)
+	assert.PanicsWithValue(t, `unknown format "grdi", available: [ascii_grid border grid headeronly plain simple]`, func() { tabulate.Render("grdi") })
+}
+
+func TestCellMaxLines(t *testing.T) {
+	tabulate := Create([][]string{{"Lorem ipsum dolor sit amet, consectetur adipiscing elit", "short"}, {"Sed do eiusmod tempor", "x"}})
+	tabulate.SetHeaders([]string{"text", "other"})
+	tabulate.SetMaxCellSize(12)
+	tabulate.SetWrapStrings(true)
+	tabulate.SetCellMaxLines(2)
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_cell_max_lines"))
+}
+
+func TestHeatmapColumn(t *testing.T) {
+	tabulate := Create([][]interface{}{{"a", 10}, {"b", 20}, {"c", 15}, {"d", "n/a"}})
+	tabulate.SetHeaders([]string{"name", "value"})
+	tabulate.SetHeatmapColumn(1)
+	rendered := tabulate.Render("simple")
+	assert.Contains(t, rendered, "\033[48;5;21m       10 \033[0m")
+	assert.Contains(t, rendered, "\033[48;5;196m       20 \033[0m")
+	assert.Contains(t, rendered, "\033[48;5;46m       15 \033[0m")
+	assert.Contains(t, rendered, "      n/a \n")
+	assert.NotContains(t, rendered, "\033[48;5;21m    value")
+}
+
+func TestHardWrapWidth(t *testing.T) {
+	tabulate := Create([][]string{{"apples", "oranges", "pears"}, {"1", "2", "3"}})
+	tabulate.SetHeaders([]string{"first", "second", "third"})
+	tabulate.SetHardWrapWidth(20)
+	assert.Equal(t, tabulate.Render("border"), readTable("_tests/test_hard_wrap_width"))
+}
+
+func TestCreateFromRows(t *testing.T) {
+	db, err := sql.Open("tabulate", "")
+	assert.Nil(t, err)
+	defer db.Close()
+
+	rows, err := db.Query("SELECT")
+	assert.Nil(t, err)
+	defer rows.Close()
+	tabulate, err := CreateFromRows(rows)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"id", "name", "joined", "note"}, tabulate.Headers)
+	assert.Equal(t, []string{"1", "Alice", "2024-03-01T00:00:00Z", "nil"}, tabulate.Data[0].Elements)
+	assert.Equal(t, []string{"2", "Bob", "2024-03-02T00:00:00Z", "admin"}, tabulate.Data[1].Elements)
+}
+
+func TestColumnWidthMode(t *testing.T) {
+	tabulate := Create([][]string{{"1", "yes"}, {"22", "no"}})
+	tabulate.SetHeaders([]string{"identifier", "enabled"})
+	tabulate.SetAlign("left")
+	assert.Contains(t, tabulate.Render("simple"), "\n identifier       enabled    \n")
+
+	tabulate.SetColumnWidthMode("content")
+	assert.Contains(t, tabulate.Render("simple"), "\n id       ena    \n")
+	assert.Contains(t, tabulate.Render("simple"), "\n 22       no     \n")
+	assert.Equal(t, []string{"identifier", "enabled"}, tabulate.Headers)
+}
+
+func TestWrapStringsColumnWidths(t *testing.T) {
+	tabulate := Create([][]string{{"ab", "Lorem ipsum dolor sit amet, consectetur adipiscing elit"}, {"c", "short"}})
+	tabulate.SetHeaders([]string{"id", "text"})
+	tabulate.SetMaxCellSize(20)
+	tabulate.SetWrapStrings(true)
+	lines := strings.Split(strings.TrimSuffix(tabulate.Render("grid"), "\n"), "\n")
+	// each column is as wide as its widest cell, and never wider than MaxSize
+	assert.Equal(t, "+-------+-----------------------+", lines[0])
+	for _, line := range lines {
+		assert.Equal(t, len(lines[0]), runewidth.StringWidth(line))
+	}
+}
+
+func mustGetFormat(t *testing.T, name string) TableFormat {
+	format, err := GetFormat(name)
+	assert.Nil(t, err)
+	return format
+}
+
+func TestRegisterFormat(t *testing.T) {
+	dots := TableFormat{
+		LineTop:   Line{".", ".", ".", "."},
+		HeaderRow: Row{":", ":", ":"},
+		DataRow:   Row{":", ":", ":"},
+		Padding:   1,
+	}
+	assert.Nil(t, RegisterFormat("dots", dots))
+	defer UnregisterFormat("dots")
+	assert.Equal(t, dots, mustGetFormat(t, "dots"))
+	assert.EqualError(t, RegisterFormat("dots", dots), `format "dots" already exists`)
+	assert.EqualError(t, RegisterFormat("grid", dots), `format "grid" already exists`)
+
+	tabulate := Create([][]string{{"a", "b"}})
+	tabulate.SetHeaders([]string{"x", "y"})
+	assert.Contains(t, tabulate.Render("dots"), ":    a :    b :")
+
+	UnregisterFormat("dots")
+	_, err := tabulate.SafeRender("dots")
+	assert.NotNil(t, err)
+}
+
+func TestPrevious(t *testing.T) {
+	tabulate := Create([][]interface{}{{"cpu", 12}, {"mem", 40}, {"disk", 7}})
+	tabulate.SetHeaders([]string{"name", "load"})
+	assert.NotContains(t, tabulate.Render("simple"), "\033[")
+
+	tabulate.SetPrevious([][]string{{"cpu", "10"}, {"mem", "40"}})
+	rendered := tabulate.Render("simple")
+	assert.Contains(t, rendered, "\n     cpu   \033[7m      12 \033[0m\n")
+	assert.Contains(t, rendered, "\n     mem         40 \n")
+	assert.Contains(t, rendered, "\n\033[7m    disk \033[0m  \033[7m       7 \033[0m\n")
+
+	tabulate.SetChangeHighlight("<", ">")
+	assert.Contains(t, tabulate.Render("simple"), "<      12 >")
+}
+
+func TestAlignByRegex(t *testing.T) {
+	tabulate := Create([][]string{{"a", "12.5"}, {"b", "pending"}, {"c", "7"}})
+	tabulate.SetHeaders([]string{"id", "value"})
+	assert.Nil(t, tabulate.SetAlignByRegex(1, `^-?[0-9.]+$`, "right", "left"))
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_align_by_regex"))
+	assert.NotNil(t, tabulate.SetAlignByRegex(1, `(`, "right", "left"))
+}
+
+func TestRenderGraphvizLabel(t *testing.T) {
+	tabulate := Create([][]interface{}{{"R&D", 12}, {"<ops>", nil}})
+	tabulate.SetHeaders([]string{"team", "size"})
+	tabulate.SetColumnTypes([]ColumnType{ColumnText, ColumnInteger})
+	expected := `<TABLE BORDER="0" CELLBORDER="1">
+<TR><TD ALIGN="LEFT"><B>team</B></TD><TD ALIGN="RIGHT"><B>size</B></TD></TR>
+<TR><TD ALIGN="LEFT">R&amp;D</TD><TD ALIGN="RIGHT">12</TD></TR>
+<TR><TD ALIGN="LEFT">&lt;ops&gt;</TD><TD ALIGN="RIGHT"></TD></TR>
+</TABLE>`
+	assert.Equal(t, expected, tabulate.RenderGraphvizLabel())
+}
+
+func TestSortByColumns(t *testing.T) {
+	tabulate := Create([][]interface{}{{"b", 10}, {"a", 9}, {"", 1}, {"b", 2}, {"a", 10}, {"c", nil}})
+	tabulate.SetHeaders([]string{"group", "n"})
+	tabulate.SortByColumns([]SortKey{{Column: 0}, {Column: 1, Descending: true}})
+	var sorted [][]string
+	for _, row := range tabulate.Data {
+		sorted = append(sorted, row.Elements)
+	}
+	assert.Equal(t, [][]string{{"a", "10"}, {"a", "9"}, {"b", "10"}, {"b", "2"}, {"c", "nil"}, {"", "1"}}, sorted)
+
+	// wrapped rows stay with their row, and the first row stays the header
+	tabulate = Create([][]string{{"name", "n"}, {"z", "1"}, {"y", "2"}})
+	tabulate.Data[1].Continuous = true
+	tabulate.SortByColumns([]SortKey{{Column: 1, Descending: true}})
+	assert.Equal(t, "name", tabulate.Data[0].Elements[0])
+	assert.Equal(t, "z", tabulate.Data[1].Elements[0])
+	assert.Equal(t, "y", tabulate.Data[2].Elements[0])
+}
+
+func TestMapMissingCells(t *testing.T) {
+	tabulate := Create(map[string][]interface{}{"a": {"x", "", "z"}, "b": {"y"}})
+	tabulate.SetEmptyString("-")
+	for _, row := range tabulate.Data {
+		if row.Elements[0] == "y" {
+			assert.Equal(t, []string{"y", "nil", "nil"}, row.Elements)
+		} else {
+			assert.Equal(t, []string{"x", "", "z"}, row.Elements)
+		}
+	}
+	assert.Contains(t, tabulate.Render("simple"), "\n    y       -       - \n")
+
+	strs := Create(map[string][]string{"a": {"x", ""}, "b": {"y"}})
+	for _, row := range strs.Data {
+		assert.Equal(t, 2, len(row.Elements))
+	}
+}
+
+func TestLineHook(t *testing.T) {
+	tabulate := Create([][]string{{"a", "b"}, {"c", "d"}})
+	tabulate.SetHeaders([]string{"x", "y"})
+	var lines []string
+	tabulate.SetLineHook(func(i int, line string) {
+		assert.Equal(t, len(lines), i)
+		lines = append(lines, line)
+	})
+	rendered := tabulate.Render("grid")
+	assert.Equal(t, rendered, strings.Join(lines, "\n")+"\n")
+	assert.Equal(t, 7, len(lines))
+}
+
+func TestHeaderWrap(t *testing.T) {
+	tabulate := Create([][]string{{"1", "Lorem ipsum dolor sit amet"}, {"2", "short"}})
+	tabulate.SetHeaders([]string{"number of the row", "text"})
+	tabulate.SetMaxCellSize(12)
+	tabulate.SetWrapStrings(true)
+	tabulate.SetIndexColumn("#")
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_header_wrap"))
+}
+
+func TestColumnEmptyString(t *testing.T) {
+	tabulate := Create([][]interface{}{{"a", 1, "2024-01-01"}, {nil, nil, nil}})
+	tabulate.SetHeaders([]string{"name", "count", "date"})
+	tabulate.SetEmptyString("?")
+	tabulate.SetColumnEmptyString(0, "Not available")
+	tabulate.SetColumnEmptyString(1, "0")
+	assert.Contains(t, tabulate.Render("simple"), "\n    Not available           0                ? \n")
+}
+
+func TestCJKWrap(t *testing.T) {
+	tabulate := Create([][]string{{"1", "hello 日本語のテキストです"}, {"2", "short"}})
+	tabulate.SetHeaders([]string{"id", "text"})
+	tabulate.SetMaxCellSize(10)
+	tabulate.SetWrapStrings(true)
+	tabulate.SetCJKWrap(true)
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_cjk_wrap"))
+}
+
+func TestColumnPrefixSuffix(t *testing.T) {
+	tabulate := Create([][]interface{}{{"a", 12.5, 30}, {"b", nil, 7}})
+	tabulate.SetHeaders([]string{"name", "price", "latency"})
+	tabulate.SetEmptyString("-")
+	tabulate.SetColumnPrefix(1, "$")
+	tabulate.SetColumnSuffix(2, "ms")
+	rendered := tabulate.Render("simple")
+	assert.Contains(t, rendered, "\n       a       $12.5          30ms \n")
+	assert.Contains(t, rendered, "\n       b           -           7ms \n")
+}
+
+func TestRenderFixed(t *testing.T) {
+	tabulate := Create([][]interface{}{{"ACME Corporation", 1250, "NY"}, {"Bob", nil, "CA"}})
+	tabulate.SetHeaders([]string{"name", "amount", "state"})
+	tabulate.SetColumnTypes([]ColumnType{ColumnText, ColumnInteger, ColumnText})
+	assert.Equal(t, "ACME Corpo  1250NY\nBob             CA\n", tabulate.RenderFixed([]int{10, 6, 2}))
+
+	// The cells are formatted like Render, kept on one line and cut by bytes
+	tabulate = Create([][]string{{"1.5", "a\nb", "c"}, {"2", "Zürich", "d"}})
+	tabulate.SetHeaders([]string{"amount", "text", "x"})
+	tabulate.SetColumnPrefix(0, "$")
+	tabulate.SetAlign("left")
+	assert.Equal(t, "$1.5a b  c\n$2  Zürid\n", tabulate.RenderFixed([]int{4, 5, 1}))
+}
+
+func TestRowColorFunc(t *testing.T) {
+	tabulate := Create([][]string{{"build", "OK"}, {"deploy", "FAILED"}})
+	tabulate.SetHeaders([]string{"step", "status"})
+	tabulate.SetRowColorFunc(func(cells []string) (string, string, bool) {
+		return "\033[31m", "\033[0m", cells[1] == "FAILED"
+	})
+	rendered := tabulate.Render("grid")
+	assert.Contains(t, rendered, "\n|     build |        OK |\n")
+	assert.Contains(t, rendered, "\n\033[31m|    deploy |    FAILED |\033[0m\n")
+}
+
+func TestHeaderCommentPrefix(t *testing.T) {
+	tabulate := Create([][]string{{"alpha", "1"}, {"beta", "22"}})
+	tabulate.SetHeaders([]string{"name", "n"})
+	tabulate.SetAlign("left")
+	tabulate.SetHeaderCommentPrefix("# ")
+	assert.Equal(t, tabulate.Render("simple"), readTable("_tests/test_header_comment"))
+
+	// formats with borders are unchanged
+	assert.NotContains(t, tabulate.Render("grid"), "#")
+}
+
+func TestRenderGrid(t *testing.T) {
+	cpu := Create([][]string{{"load", "0.5"}, {"temp", "61"}})
+	cpu.SetHeaders([]string{"cpu", "value"})
+	cpu.TableFormat = mustGetFormat(t, "grid")
+	mem := Create([][]string{{"used", "12G"}})
+	mem.SetHeaders([]string{"mem", "value"})
+	mem.TableFormat = mustGetFormat(t, "grid")
+	disk := Create([][]string{{"/", "81%"}})
+	disk.SetHeaders([]string{"disk", "use"})
+	disk.TableFormat = mustGetFormat(t, "simple")
+	assert.Equal(t, RenderGrid([][]*Tabulate{{cpu, mem}, {disk, nil}}, 2), readTable("_tests/test_render_grid"))
+	assert.Equal(t, strings.Split(strings.TrimSuffix(mem.Render(), "\n"), "\n"), mem.RenderLines())
+
+	// the escape codes of colored tables take no room in the grid
+	colored := Create([][]string{{"a", "-1"}})
+	colored.SetHeaders([]string{"x", "y"})
+	colored.SetNegativeColor("\033[31m", "\033[0m")
+	colored.TableFormat = mustGetFormat(t, "simple")
+	plain := Create([][]string{{"b"}})
+	plain.SetHeaders([]string{"z"})
+	plain.TableFormat = mustGetFormat(t, "simple")
+	for _, line := range strings.Split(RenderGrid([][]*Tabulate{{colored, plain}}, 1), "\n") {
+		if len(line) > 0 {
+			assert.Equal(t, 22, visibleWidth(line))
+		}
+	}
+}
+
+func TestDataColor(t *testing.T) {
+	tabulate := Create([][]interface{}{{"a", 1, -2}, {"b", 3, 4}})
+	tabulate.SetHeaders([]string{"name", "x", "y"})
+	tabulate.SetDataColor("<", ">")
+	tabulate.SetNegativeColor("[", "]")
+	tabulate.SetHeatmapColumn(1)
+	rendered := tabulate.Render("simple")
+	assert.Contains(t, rendered, "\n    name       x        y \n")
+	assert.Contains(t, rendered, "\n<       a >  \033[48;5;21m    1 \033[0m  [    -2 ]\n")
+	assert.Contains(t, rendered, "\n<       b >  \033[48;5;196m    3 \033[0m  <     4 >\n")
+}
+
+func TestColumnWidthPercent(t *testing.T) {
+	tabulate := Create([][]string{{"a", "the quick brown fox jumps over the lazy dog"}})
+	tabulate.SetHeaders([]string{"id", "text"})
+	tabulate.SetMaxTableWidth(40)
+	assert.Nil(t, tabulate.SetColumnWidthPercent(1, 50))
+	assert.NotNil(t, tabulate.SetColumnWidthPercent(0, 60))
+	assert.NotNil(t, tabulate.SetColumnWidthPercent(0, 0))
+	rendered := tabulate.Render("simple")
+	assert.Contains(t, rendered, "\n-------  -------------------\n")
+	assert.Contains(t, rendered, "\n     a           the quick  \n                 brown fox  \n")
+	assert.Contains(t, rendered, "\n               the lazy dog \n")
+}
+
+func TestShowRowCount(t *testing.T) {
+	tabulate := Create([][]string{{"a", "one two"}, {"b", "three"}})
+	tabulate.SetHeaders([]string{"id", "text"})
+	tabulate.SetShowRowCount(true)
+	tabulate.SetWrapStrings(true)
+	tabulate.SetMaxCellSize(4)
+	rendered := tabulate.Render("simple")
+	assert.True(t, strings.HasSuffix(rendered, "---------\n(2 rows)\n"), rendered)
+
+	tabulate = Create([][]string{{"a"}})
+	tabulate.SetHeaders([]string{"id"})
+	tabulate.SetShowRowCount(true)
+	assert.True(t, strings.HasSuffix(tabulate.Render("simple"), "\n(1 row)\n"))
+	tabulate.SetRowCountFormat("%d result(s)")
+	assert.True(t, strings.HasSuffix(tabulate.Render("simple"), "\n1 result(s)\n"))
+}
+
+func TestAlignOnChar(t *testing.T) {
+	tabulate := Create([][]string{{"9:05", "x=1"}, {"12:30", "long=22"}, {"noon", "y"}})
+	tabulate.SetHeaders([]string{"time", "assignment"})
+	tabulate.SetAlignOnChar(0, ':')
+	tabulate.SetAlignOnChar(1, '=')
+	tabulate.SetAlign("left")
+	rendered := tabulate.Render("simple")
+	assert.Contains(t, rendered, "\n    9:05          x=1        \n")
+	assert.Contains(t, rendered, "\n   12:30       long=22       \n")
+	assert.Contains(t, rendered, "\n noon             y          \n")
+}
+
+func TestNaturalWidths(t *testing.T) {
+	tabulate := Create([][]string{{"name", "description"}, {"a", "a rather long description"}, {"bb", "short"}})
+	tabulate.SetWrapStrings(true)
+	tabulate.SetMaxCellSize(8)
+	assert.Equal(t, []int{4, 25}, tabulate.NaturalWidths())
+	assert.Empty(t, tabulate.Headers)
+	tabulate.Render("simple")
+	assert.Equal(t, []int{4, 25}, tabulate.NaturalWidths())
+	assert.Nil(t, Create([][]string{}).NaturalWidths())
+
+	// the cells are measured as they are rendered, with their notes and without the columns past MaxColumns
+	tabulate = Create([][]string{{"a", "b", "c"}})
+	tabulate.SetHeaders([]string{"x", "y", "z"})
+	tabulate.AddNote(0, 0, "first")
+	tabulate.SetMaxColumns(1)
+	assert.Equal(t, []int{2, 1}, tabulate.NaturalWidths())
+
+	// the render state is left untouched
+	tabulate.headers, tabulate.footer, tabulate.total, tabulate.notes = []string{"h1", "h2"}, []string{"f1", "f2"}, []string{"t1", "t2"}, []string{"n"}
+	assert.Equal(t, []int{2, 1}, tabulate.NaturalWidths())
+	assert.Equal(t, []string{"h1", "h2"}, tabulate.headers)
+	assert.Equal(t, []string{"f1", "f2"}, tabulate.footer)
+	assert.Equal(t, []string{"t1", "t2"}, tabulate.total)
+	assert.Equal(t, []string{"n"}, tabulate.notes)
+	assert.Equal(t, []string{"x", "y", "z"}, tabulate.Headers)
+}
+
+func TestSeparatorChars(t *testing.T) {
+	tabulate := Create([][]string{{"a", "b"}, {"c", "d"}})
+	tabulate.SetHeaders([]string{"h1", "h2"})
+	tabulate.SetHeaderSeparatorChar('~')
+	tabulate.SetRowSeparatorChar('.')
+	rendered := tabulate.Render("grid")
+	assert.Contains(t, rendered, "\n+~~~~~~~+~~~~~~~+\n")
+	assert.Contains(t, rendered, "\n+.......+.......+\n")
+	assert.True(t, strings.HasPrefix(rendered, "+-------+-------+\n"))
+	assert.Equal(t, "=", mustGetFormat(t, "grid").LineBelowHeader.hline)
+
+	// Formats without lines between rows get no new line
+	assert.NotContains(t, tabulate.Render("simple"), "...")
+}
+
+func TestVerbatimColumns(t *testing.T) {
+	tabulate := Create([][]string{{"if ok {\n    return\n}", "a long comment here"}})
+	tabulate.SetHeaders([]string{"code", "comment"})
+	tabulate.SetVerbatimColumns([]int{0})
+	tabulate.SetWrapStrings(true)
+	tabulate.SetMaxCellSize(6)
+	tabulate.SetAlign("left")
+	rendered := tabulate.Render("simple")
+	assert.Contains(t, rendered, "\n if ok {          a         \n     return       long      \n }                commen    \n")
+}
+
+func TestMergeEqualHeaders(t *testing.T) {
+	tabulate := Create([][]string{{"a", "1", "2", "3"}, {"b", "4", "5", "6"}})
+	tabulate.SetHeaders([]string{"name", "2024", "2024", "2025"})
+	tabulate.SetMergeEqualHeaders(true)
+	rendered := tabulate.Render("grid")
+	assert.Contains(t, rendered, "\n|    name |        2024       |    2025 |\n")
+	assert.Contains(t, rendered, "\n|       a |       1 |       2 |       3 |\n")
+}
+
+func TestGrandTotal(t *testing.T) {
+	tabulate := Create([][]interface{}{{"apples", 3, 1.5}, {"pears", 4, 2.25}})
+	tabulate.SetHeaders([]string{"fruit", "count", "price"})
+	tabulate.SetColumnPrefix(2, "$")
+	tabulate.SetGrandTotal("TOTAL", []int{1, 2})
+	tabulate.SetFloatFormat('f')
+	rendered := tabulate.Render("grid")
+	assert.True(t, strings.HasSuffix(rendered, "+===========+==========+==========+\n|     TOTAL |        7 |    $3.75 |\n+-----------+----------+----------+\n"))
+}
+
+func TestStrictColumns(t *testing.T) {
+	tabulate := Create([][]string{{"a", "b"}, {"c"}, {"d", "e"}})
+	tabulate.SetHeaders([]string{"h1", "h2"})
+	_, err := tabulate.SafeRender("simple")
+	assert.Nil(t, err)
+
+	tabulate.SetStrictColumns(true)
+	_, err = tabulate.SafeRender("simple")
+	assert.EqualError(t, err, "row 1 has 1 columns, expected 2")
+	assert.Panics(t, func() { tabulate.Render("simple") })
+}
+
+func TestRenderPaged(t *testing.T) {
+	tabulate := Create([][]string{{"a", "one two"}, {"b", "x"}, {"c", "y"}})
+	tabulate.SetHeaders([]string{"id", "text"})
+	tabulate.SetWrapStrings(true)
+	tabulate.SetMaxCellSize(4)
+	pages := strings.Split(tabulate.RenderPaged(2, "simple"), "\f")
+	assert.Equal(t, 2, len(pages))
+	assert.Equal(t, "-------  ---------\n    id       text \n-------  ---------\n     a       one  \n              two \n\n     b          x \n-------  ---------\n", pages[0])
+	assert.Equal(t, "-------  ---------\n    id       text \n-------  ---------\n     c          y \n-------  ---------\n", pages[1])
+}
+
+func TestColumnWidthRange(t *testing.T) {
+	tabulate := Create([][]string{{"a", "the quick brown fox", "x"}})
+	tabulate.SetHeaders([]string{"id", "text", "flag"})
+	tabulate.SetColumnWidthRange(0, 6, 10)
+	tabulate.SetColumnWidthRange(1, 0, 10)
+	tabulate.SetAlign("left")
+	rendered := tabulate.Render("grid")
+	assert.Contains(t, rendered, "\n| a         | the quick     | x       |\n|           | brown fox     |         |\n")
+}
+
+func TestReverseColumns(t *testing.T) {
+	tabulate := Create([][]string{{"a", "long value", "1"}, {"b", "x", "22"}})
+	tabulate.SetHeaders([]string{"id", "text", "n"})
+	tabulate.SetColumnSeparators([]string{" | "})
+	tabulate.SetIndexColumn("#")
+	tabulate.SetReverseColumns(true)
+	rendered := tabulate.Render("grid")
+	assert.True(t, strings.HasPrefix(rendered, "+------+-------+---------------+---------+\n|    # |     n |          text  |     id |\n"))
+	assert.Contains(t, rendered, "\n|    2 |    22 |             x  |      b |\n")
+}
+
+func TestAutoSizeShrinkHeaders(t *testing.T) {
+	headers := []string{"a very long header", "another long header", "yet another header", "short"}
+	tabulate := Create([][]string{{"1", "2", "3", "4"}})
+	tabulate.SetHeaders(headers)
+	tabulate.SetAutoSize(true)
+	tabulate.SetMaxTableWidth(50)
+	assert.True(t, runewidth.StringWidth(tabulate.RenderLines("grid")[0]) > 50)
+
+	tabulate = Create([][]string{{"1", "2", "3", "4"}})
+	tabulate.SetHeaders(headers)
+	tabulate.SetAutoSize(true)
+	tabulate.SetMaxTableWidth(50)
+	tabulate.SetAutoSizeShrinkHeaders(3)
+	lines := tabulate.RenderLines("grid")
+	assert.True(t, runewidth.StringWidth(lines[0]) <= 50)
+	assert.Equal(t, "| a very lo… | another l… | yet anothe… | short |", lines[1])
+}
+
+func TestHideEmptyColumns(t *testing.T) {
+	tabulate := Create([][]interface{}{{"a", "", 1, nil}, {"b", " ", 2}})
+	tabulate.SetHeaders([]string{"name", "empty", "n", "missing"})
+	tabulate.SetHideEmptyColumns(true)
+	assert.Equal(t, "+---------+------+\n|    name |    n |\n+=========+======+\n|       a |    1 |\n+---------+------+\n|       b |    2 |\n+---------+------+\n", tabulate.Render("grid"))
+	assert.Equal(t, []string{"a", "", "1", "nil"}, tabulate.Data[0].Elements)
+}
+
+func TestQuick(t *testing.T) {
+	tabulate := Create([][]string{STRING_ARRAY, STRING_ARRAY})
+	tabulate.SetHeaders(HEADERS)
+	assert.Equal(t, tabulate.Render("grid"), Quick(HEADERS, [][]string{STRING_ARRAY, STRING_ARRAY}, "grid"))
+	assert.Equal(t, Quick(HEADERS, [][]string{STRING_ARRAY}, "grid"), Quick(HEADERS, [][]string{STRING_ARRAY}, ""))
+}
+
+func TestBorderlessWrappedRows(t *testing.T) {
+	tabulate := Create([][]string{{"a", "one two"}, {"b", "three"}})
+	tabulate.SetHeaders([]string{"id", "text"})
+	tabulate.SetWrapStrings(true)
+	tabulate.SetMaxCellSize(4)
+	// The logical rows are separated by a blank line, their continuation rows are not
+	assert.Equal(t, "\n    id       text \n\n     a       one  \n              two \n\n     b       thre \n                e \n\n", tabulate.Render("plain"))
+}
+
+func TestTrimTrailingZeros(t *testing.T) {
+	tabulate := Create([][]interface{}{{1.5, "v1.0"}, {12.25, "2.000"}, {2.0, "x"}})
+	tabulate.SetHeaders([]string{"n", "text"})
+	tabulate.SetColumnTypes([]ColumnType{ColumnCurrency, ColumnText})
+	tabulate.SetTrimTrailingZeros(true)
+	tabulate.SetAlignOnChar(0, '.')
+	tabulate.SetAlign("left")
+	rendered := tabulate.Render("simple")
+	assert.Contains(t, rendered, "\n  1.5        v1.0     \n\n 12.25       2.000    \n\n  2          x        \n")
+	assert.Equal(t, "-1,5", trimZeros("-1,500", ",", '.'))
+	assert.Equal(t, "1.000", trimZeros("1.000", ",", '.'))
+	assert.Equal(t, "0", trimZeros(".000", ".", 0))
+	assert.Equal(t, "-0.5", trimZeros("-.500", ".", 0))
+}
+
+func TestVerticalHeaders(t *testing.T) {
+	tabulate := Create([][]int{{1, 22, 3}})
+	tabulate.SetHeaders([]string{"cpu", "mem", "表示"})
+	tabulate.SetVerticalHeaders(true)
+	assert.Equal(t, "+------+-------+-------+\n|    c |     m |    表 |\n|    p |     e |    示 |\n|    u |     m |       |\n+======+=======+=======+\n|    1 |    22 |     3 |\n+------+-------+-------+\n", tabulate.Render("grid"))
+}
+
+func TestPadFillFunc(t *testing.T) {
+	tabulate := Create([][]string{{"a", "1"}, {"bb", "22"}, {"c", "333"}})
+	tabulate.SetHeaders([]string{"name", "n"})
+	tabulate.SetAlign("left")
+	tabulate.SetPadFillFunc(func(row, col int, content string) rune {
+		if row == 1 && col == 0 {
+			return '.'
+		}
+		if col == 1 && len(content) < 2 {
+			return '表'
+		}
+		return ' '
+	})
+	rendered := tabulate.Render("simple")
+	assert.Contains(t, rendered, "\n a          1      \n\n bb .....   22     \n\n c          333    \n")
+	assert.Contains(t, rendered, "\n name       n      \n")
+
+	// the rows are numbered the same way without lines
+	separated := tabulate.RenderWithSeparator("|")
+	assert.Equal(t, " name    | n      \n a       | 1      \n bb .....| 22     \n c       | 333    \n", separated)
+}
+
+func TestHeaderOnlyFormat(t *testing.T) {
+	tabulate := Create([][]string{STRING_ARRAY, STRING_ARRAY})
+	tabulate.SetHeaders(HEADERS)
+	tabulate.SetAlign("left")
+	assert.Equal(t, tabulate.Render("headeronly"), readTable("_tests/test_headeronly"))
+}
+
+func TestHyphenateWrap(t *testing.T) {
+	tabulate := Create([][]string{{"internationalization is long"}})
+	tabulate.SetHeaders([]string{"text"})
+	tabulate.SetWrapStrings(true)
+	tabulate.SetMaxCellSize(8)
+	tabulate.SetHyphenateWrap(true)
+	tabulate.SetAlign("left")
+	assert.Equal(t, "-------------\n text        \n-------------\n interna-    \n tionali-    \n zation      \n is long     \n-------------\n", tabulate.Render("simple"))
+}
+
+func TestColumnComparator(t *testing.T) {
+	tabulate := Create([][]string{{"1.10", "done"}, {"1.9", "todo"}, {"1.2", "doing"}, {"", "done"}})
+	tabulate.SetHeaders([]string{"version", "status"})
+	tabulate.SetColumnComparator(0, func(a, b string) bool {
+		pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+		for i := 0; i < len(pa) && i < len(pb); i++ {
+			na, _ := strconv.Atoi(pa[i])
+			nb, _ := strconv.Atoi(pb[i])
+			if na != nb {
+				return na < nb
+			}
+		}
+		return len(pa) < len(pb)
+	})
+	order := map[string]int{"todo": 0, "doing": 1, "done": 2}
+	tabulate.SetColumnComparator(1, func(a, b string) bool { return order[a] < order[b] })
+
+	tabulate.SortByColumns([]SortKey{{Column: 0}})
+	var sorted []string
+	for _, row := range tabulate.Data {
+		sorted = append(sorted, row.Elements[0])
+	}
+	assert.Equal(t, []string{"1.2", "1.9", "1.10", ""}, sorted)
+
+	tabulate.SortByColumns([]SortKey{{Column: 1, Descending: true}, {Column: 0}})
+	sorted = nil
+	for _, row := range tabulate.Data {
+		sorted = append(sorted, row.Elements[1]+" "+row.Elements[0])
+	}
+	assert.Equal(t, []string{"done 1.10", "done ", "doing 1.2", "todo 1.9"}, sorted)
+}
+
+func TestASCIIGridFormat(t *testing.T) {
+	tabulate := Create([][]string{STRING_ARRAY, STRING_ARRAY})
+	tabulate.SetHeaders(HEADERS)
+	rendered := tabulate.Render("ascii_grid")
+	assert.Equal(t, rendered, readTable("_tests/test_ascii_grid"))
+	assert.NotContains(t, rendered, "=")
+	// The junctions of every line are below the separators of the rows
+	junctions := func(line string, glyph rune) (positions []int) {
+		for i, r := range line {
+			if r == glyph {
+				positions = append(positions, i)
+			}
+		}
+		return positions
+	}
+	lines := strings.Split(strings.TrimSuffix(rendered, "\n"), "\n")
+	for i := 0; i < len(lines); i += 2 {
+		assert.Equal(t, junctions(lines[1], '|'), junctions(lines[i], '+'))
+	}
+}
+
+func TestColumnTruncateSide(t *testing.T) {
+	tabulate := Create([][]string{{"/home/user/deep/path/file.go", "/home/user/deep/path/file.go", "a b"}})
+	tabulate.SetHeaders([]string{"left", "middle", "right"})
+	tabulate.SetWrapStrings(true)
+	tabulate.SetMaxCellSize(12)
+	tabulate.SetColumnTruncateSide(0, "left")
+	tabulate.SetColumnTruncateSide(1, "middle")
+	tabulate.SetAlign("left")
+	assert.Contains(t, tabulate.Render("simple"), "\n …ath/file.go       /home/…le.go       a b      \n")
+	assert.Equal(t, "表…", tabulate.truncateSide("表示表示", 3, "right"))
+	assert.Equal(t, "…示", tabulate.truncateSide("表示表示", 4, "left"))
+}
+
+func TestRenderContext(t *testing.T) {
+	tabulate := Create([][]string{STRING_ARRAY, STRING_ARRAY, EMPTY_ARRAY})
+	tabulate.SetHeaders(HEADERS)
+	tabulate.SetEmptyString("None")
+	rendered, err := tabulate.RenderContext(context.Background(), "grid")
+	assert.Nil(t, err)
+	assert.Equal(t, rendered, readTable("_tests/grid_strings"))
+
+	_, err = tabulate.RenderContext(context.Background(), "grdi")
+	assert.EqualError(t, err, `unknown format "grdi", available: [ascii_grid border grid headeronly plain simple]`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = tabulate.RenderContext(ctx, "grid")
+	assert.True(t, errors.Is(err, context.Canceled))
+	// the terminal is not read once the rendering is canceled
+	_, err = tabulate.tableWidth(ctx)
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	data := make([][]string, 200000)
+	for i := range data {
+		data[i] = STRING_ARRAY
+	}
+	tabulate = Create(data)
+	tabulate.SetRenderTimeout(time.Millisecond)
+	_, err = tabulate.RenderContext(context.Background(), "grid")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	// the first row is not popped as headers by a canceled rendering
+	assert.Empty(t, tabulate.Headers)
+	assert.Equal(t, len(data), len(tabulate.Data))
+}
+
+func TestSparseColumns(t *testing.T) {
+	tabulate := Create([][]string{
+		{"fruit", "apple", "red"},
+		{"fruit", "apple", "green"},
+		{"fruit", "pear", "green"},
+		{"vegetable", "pear", "green"},
+	})
+	tabulate.SetHeaders([]string{"kind", "name", "color"})
+	tabulate.SetSparseColumns([]int{1, 0})
+	tabulate.SetAlign("left")
+	assert.Equal(t, "--------------  ----------  ----------\n kind            name        color    \n--------------  ----------  ----------\n fruit           apple       red      \n\n                             green    \n\n                 pear        green    \n\n vegetable       pear        green    \n--------------  ----------  ----------\n", tabulate.Render("simple"))
+	assert.Equal(t, "apple", tabulate.Data[1].Elements[1])
+}
+
+func TestWrapCarriageReturn(t *testing.T) {
+	tabulate := Create([][]string{{"a\r\nb", "c\rd"}})
+	tabulate.SetHeaders([]string{"x", "y"})
+	tabulate.SetWrapStrings(true)
+	tabulate.SetMaxCellSize(5)
+	tabulate.SetAlign("left")
+	assert.Equal(t, "\n x       y    \n\n a       c    \n b       d    \n\n", tabulate.Render("plain"))
+}
+
+func TestHeaderIcon(t *testing.T) {
+	tabulate := Create([][]string{{"a", "b"}})
+	tabulate.SetHeaders([]string{"id", "name"})
+	tabulate.SetHeaderIcon(1, "▲")
+	tabulate.UpperHeaders()
+	assert.Equal(t, "-------  -----------\n    ID       ▲ NAME \n-------  -----------\n     a            b \n-------  -----------\n", tabulate.Render("simple"))
+}
+
+func TestColumnZebra(t *testing.T) {
+	tabulate := Create([][]string{{"a", "b", "c"}})
+	tabulate.SetHeaders([]string{"x", "y", "z"})
+	tabulate.SetColumnZebra("<", ">", "[", "]")
+	tabulate.SetAlign("left")
+	assert.Equal(t, "\n x       y       z    \n\n< a    >  [ b    ]  < c    >\n\n", tabulate.Render("plain"))
+	tabulate.SetColumnZebraHeaders(true)
+	assert.Equal(t, "\n< x    >  [ y    ]  < z    >\n\n< a    >  [ b    ]  < c    >\n\n", tabulate.Render("plain"))
+}
+
+func TestCurrencyColumn(t *testing.T) {
+	tabulate := Create([][]string{{"rent", "1,234"}, {"tip", "$5"}, {"none", ""}})
+	tabulate.SetHeaders([]string{"item", "cost"})
+	tabulate.SetCurrencyColumn(1, "$")
+	tabulate.SetAlign("left")
+	assert.Equal(t, "---------  ------------\n item       cost       \n---------  ------------\n rent       $ 1,234    \n\n tip        $     5    \n\n none                  \n---------  ------------\n", tabulate.Render("simple"))
+}
+
+func TestRowVerticalPadding(t *testing.T) {
+	tabulate := Create([][]string{{"a", "b"}, {"c", "d"}})
+	tabulate.SetHeaders([]string{"x", "y"})
+	tabulate.SetIndexColumn("#")
+	tabulate.SetRowVerticalPadding(1, 1)
+	assert.Equal(t, "+------+------+------+\n|    # |    x |    y |\n+======+======+======+\n|      |      |      |\n|    1 |    a |    b |\n|      |      |      |\n+------+------+------+\n|      |      |      |\n|    2 |    c |    d |\n|      |      |      |\n+------+------+------+\n", tabulate.Render("grid"))
+	tabulate.SetRowVerticalPadding(0, 0)
+	tabulate.SetHeaderVerticalPadding(1, 0)
+	assert.Equal(t, "+------+------+------+\n|      |      |      |\n|    # |    x |    y |\n+======+======+======+\n|    1 |    a |    b |\n+------+------+------+\n|    2 |    c |    d |\n+------+------+------+\n", tabulate.Render("grid"))
+}
+
+func TestClone(t *testing.T) {
+	base := Create([][]string{{"a", "b"}, {"c", "d"}})
+	base.SetHeaders([]string{"x", "y"})
+	base.SetHeaderIcon(0, "*")
+	expected := base.Render("simple")
+
+	clone := base.Clone()
+	clone.Data[0].Elements[0] = "changed"
+	clone.Headers[1] = "z"
+	clone.SetHeaderIcon(0, "#")
+	clone.SetHideLines([]string{"top"})
+	clone.SetAlign("left")
+	assert.NotEqual(t, expected, clone.Render("simple"))
+
+	assert.Equal(t, expected, base.Render("simple"))
+	assert.Equal(t, "a", base.Data[0].Elements[0])
+	assert.Equal(t, []string{"x", "y"}, base.Headers)
+	assert.Equal(t, "*", base.HeaderIcons[0])
+}
+
+func TestTruncateInsteadOfWrap(t *testing.T) {
+	tabulate := Create([][]string{{"a long sentence", "/usr/local/bin/tool"}, {"short", "x"}})
+	tabulate.SetHeaders([]string{"text", "path"})
+	tabulate.SetMaxCellSize(8)
+	tabulate.SetTruncateInsteadOfWrap(true)
+	tabulate.SetColumnTruncateSide(1, "left")
+	tabulate.SetAlign("left")
+	assert.Equal(t, "-------------  -------------\n text           path        \n-------------  -------------\n a long …       …in/tool    \n\n short          x           \n-------------  -------------\n", tabulate.Render("simple"))
+}
+
+func TestShowSortIndicators(t *testing.T) {
+	tabulate := Create([][]string{{"b", "1", "x"}, {"a", "2", "y"}, {"a", "3", "z"}})
+	tabulate.SetHeaders([]string{"name", "n", "other"})
+	tabulate.SetShowSortIndicators(true)
+	tabulate.SetAlign("left")
+	unsorted := tabulate.Render("simple")
+	assert.Contains(t, unsorted, " name ")
+	assert.NotContains(t, unsorted, "▲")
+
+	tabulate.SortByColumns([]SortKey{{Column: 0}, {Column: 1, Descending: true}})
+	assert.Equal(t, "-----------  --------  ----------\n name ▲       n ▼       other    \n-----------  --------  ----------\n a            3         z        \n\n a            2         y        \n\n b            1         x        \n-----------  --------  ----------\n", tabulate.Render("simple"))
+
+	tabulate.SetShowSortIndicators(false)
+	assert.NotContains(t, tabulate.Render("simple"), "▼")
+}
+
+func TestInsertRow(t *testing.T) {
+	tabulate := Create([][]interface{}{{"a", 1}, {"b", 2}})
+	tabulate.SetHeaders([]string{"name", "count"})
+	tabulate.InsertRow(1, "subtotal", 1.5)
+	tabulate.InsertRow(-1, "first", nil)
+	tabulate.InsertRow(10, "last", true)
+
+	var names []string
+	for _, row := range tabulate.Data {
+		names = append(names, row.Elements[0])
+	}
+	assert.Equal(t, []string{"first", "a", "subtotal", "b", "last"}, names)
+	assert.Equal(t, []string{"subtotal", "1.5"}, tabulate.Data[2].Elements)
+	assert.Equal(t, "nil", tabulate.Data[0].Elements[1])
+}
+
+func TestEmptyStringWidth(t *testing.T) {
+	tabulate := Create([][]interface{}{{"a", nil}, {nil, "b"}})
+	tabulate.SetHeaders([]string{"x", "y"})
+	tabulate.SetEmptyString("not available")
+	tabulate.SetAlign("left")
+	assert.Equal(t, "+------------------+------------------+\n| x                | y                |\n+==================+==================+\n| a                | not available    |\n+------------------+------------------+\n| not available    | b                |\n+------------------+------------------+\n", tabulate.Render("grid"))
+}
+
+func TestSortMixedColumn(t *testing.T) {
+	for _, cells := range [][]string{{"9", "10", "1a"}, {"1a", "10", "9"}, {"10", "1a", "9"}} {
+		tabulate := Create([][]string{{cells[0]}, {cells[1]}, {cells[2]}})
+		tabulate.SetHeaders([]string{"x"})
+		tabulate.SortByColumns([]SortKey{{Column: 0}})
+		var sorted []string
+		for _, row := range tabulate.Data {
+			sorted = append(sorted, row.Elements[0])
+		}
+		assert.Equal(t, []string{"9", "10", "1a"}, sorted)
+	}
+}
+
+func TestLocaleNumbers(t *testing.T) {
+	tabulate := Create([][]interface{}{{"a", 1234.5}, {"b", -2.25}, {"c", 10.0}})
+	tabulate.SetHeaders([]string{"name", "n"})
+	tabulate.SetLocale(',', '.')
+	tabulate.SetNegativeColor("[", "]")
+	tabulate.SetAutoFooter(map[int]string{1: "sum"})
+	tabulate.SortByColumns([]SortKey{{Column: 1}})
+	assert.Equal(t, "---------  -------------\n    name              n \n---------  -------------\n       b   [       -2,25 ]\n\n       c             10 \n\n       a        1.234,5 \n---------  -------------\n               1.242,25 \n---------  -------------\n", tabulate.Render("simple"))
+}
+
+func TestMultiRuneLines(t *testing.T) {
+	assert.Nil(t, RegisterFormat("equals", TableFormat{
+		LineTop:    Line{"+", "-=", "+", "+"},
+		LineBottom: Line{"+", "-=", "+", "+"},
+		HeaderRow:  Row{"|", "|", "|"},
+		DataRow:    Row{"|", "|", "|"},
+		Padding:    1,
+	}))
+	defer UnregisterFormat("equals")
+	tabulate := Create([][]string{{"a", "bb"}})
+	tabulate.SetHeaders([]string{"x", "y"})
+	assert.Equal(t, "+-=-=-=+-=-=-= +\n|    x |     y |\n\n|    a |    bb |\n+-=-=-=+-=-=-= +\n", tabulate.Render("equals"))
+
+	// Box drawing glyphs are two columns wide in East Asian locales
+	defer func(eastAsian bool) { runewidth.DefaultCondition.EastAsianWidth = eastAsian }(runewidth.DefaultCondition.EastAsianWidth)
+	runewidth.DefaultCondition.EastAsianWidth = true
+	lines := strings.Split(tabulate.Render("border"), "\n")
+	assert.Equal(t, "┏━━━┳━━━ ┓", lines[0])
+	assert.Equal(t, "└───┴─── ┘", lines[4])
+}
+
+func TestContinuationMarker(t *testing.T) {
+	tabulate := Create([][]string{{"Lorem ipsum dolor sit amet, consectetur adipiscing elit", "short"}, {"test", "row"}})
+	tabulate.SetHeaders([]string{"text", "other"})
+	tabulate.SetMaxCellSize(16)
+	tabulate.SetWrapStrings(true)
+	tabulate.SetAlign("left")
+	tabulate.SetContinuationMarker("↳ ")
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_continuation_marker"))
+}
+
+func TestRenderWindow(t *testing.T) {
+	tabulate := Create([][]string{{"first"}, {"Lorem ipsum dolor sit amet"}, {"third"}, {"fourth"}})
+	tabulate.SetHeaders([]string{"text"})
+	tabulate.SetMaxCellSize(12)
+	tabulate.SetWrapStrings(true)
+	output, total := tabulate.RenderWindow(1, 2, "grid")
+	assert.Equal(t, output, readTable("_tests/test_render_window"))
+	assert.Equal(t, 4, total)
+}
+
+func TestWrapIndent(t *testing.T) {
+	tabulate := Create([][]string{{"Lorem ipsum dolor sit amet, consectetur adipiscing elit", "short"}, {"test", "row"}})
+	tabulate.SetHeaders([]string{"text", "other"})
+	tabulate.SetMaxCellSize(16)
+	tabulate.SetWrapStrings(true)
+	tabulate.SetAlign("left")
+	tabulate.SetWrapIndent(2)
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_wrap_indent"))
+}
+
+func TestSubTable(t *testing.T) {
+	sub := Create([][]string{{"cpu", "90%"}, {"mem", "2G"}})
+	sub.SetHeaders([]string{"res", "use"})
+	tabulate := Create([][]interface{}{{"host-1", sub, "up"}, {"host-2", "none", "down"}})
+	tabulate.SetHeaders([]string{"host", "usage", "status"})
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_sub_table"))
+}
+
+func TestIndexColumn(t *testing.T) {
+	tabulate := Create([][]string{{"first", "a"}, {"Lorem ipsum dolor sit amet", "b"}, {"third", "c"}})
+	tabulate.SetHeaders([]string{"text", "other"})
+	tabulate.SetMaxCellSize(12)
+	tabulate.SetWrapStrings(true)
+	tabulate.SetIndexColumn("#")
+	tabulate.SetColumnPadding([]int{2})
+	assert.Equal(t, tabulate.Render("grid"), readTable("_tests/test_index_column"))
+}
+
 func TestMultiByteString(t *testing.T) {
 	tabulate := Create([][]string{
 		{"朝", "おはようございます"},
@@ -202,6 +1517,22 @@ func TestBorderString(t *testing.T) {
 	assert.Equal(t, tabulate.Render("border"), readTable("_tests/border_strings"))
 }
 
+func benchmarkRender(b *testing.B, fast bool) {
+	data := make([][]string, 100000)
+	for i := range data {
+		data[i] = STRING_ARRAY
+	}
+	for n := 0; n < b.N; n++ {
+		tabulate := Create(data)
+		tabulate.SetHeaders(HEADERS)
+		tabulate.SetASCIIFast(fast)
+		tabulate.Render("grid")
+	}
+}
+
+func BenchmarkRender(b *testing.B)          { benchmarkRender(b, false) }
+func BenchmarkRenderASCIIFast(b *testing.B) { benchmarkRender(b, true) }
+
 func readTable(path string) string {
 	buf, err := ioutil.ReadFile(path)
 	if err != nil {
@@ -209,3 +1540,40 @@ func readTable(path string) string {
 	}
 	return string(buf)
 }
+
+// Driver returning fixed rows, to test CreateFromRows
+type testDriver struct{}
+type testConn struct{}
+type testStmt struct{}
+type testRows struct{ index int }
+
+var testRowsData = [][]driver.Value{
+	{int64(1), []byte("Alice"), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), nil},
+	{int64(2), "Bob", time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), []byte("admin")},
+}
+
+func init() {
+	sql.Register("tabulate", testDriver{})
+}
+
+func (testDriver) Open(name string) (driver.Conn, error)   { return testConn{}, nil }
+func (testConn) Prepare(query string) (driver.Stmt, error) { return testStmt{}, nil }
+func (testConn) Close() error                              { return nil }
+func (testConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+func (testStmt) Close() error                              { return nil }
+func (testStmt) NumInput() int                             { return 0 }
+func (testStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (testStmt) Query(args []driver.Value) (driver.Rows, error) { return &testRows{}, nil }
+func (*testRows) Columns() []string                             { return []string{"id", "name", "joined", "note"} }
+func (*testRows) Close() error                                  { return nil }
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if r.index >= len(testRowsData) {
+		return io.EOF
+	}
+	copy(dest, testRowsData[r.index])
+	r.index++
+	return nil
+}
@@ -1,45 +1,35 @@
 package gotabulate
 
-import "strconv"
-import "fmt"
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mattn/go-runewidth"
+)
 
 // Create normalized Array from strings
+// Rows are copied, so rendering never changes the data of the caller
 func createFromString(data [][]string) []*TabulateRow {
 	rows := make([]*TabulateRow, len(data))
 
 	for index, el := range data {
-		rows[index] = &TabulateRow{Elements: el}
+		rows[index] = &TabulateRow{Elements: append([]string(nil), el...)}
 	}
 	return rows
 }
 
 // Create normalized array of rows from mixed data (interface{})
-func createFromMixed(data [][]interface{}, format byte) []*TabulateRow {
+// The source values are kept so they can be formatted again when a format option changes
+func createFromMixed(data [][]interface{}) []*TabulateRow {
 	rows := make([]*TabulateRow, len(data))
-	for index_1, element := range data {
-		normalized := make([]string, len(element))
-		for index, el := range element {
-			switch el.(type) {
-			case int32:
-				quoted := strconv.QuoteRuneToASCII(el.(int32))
-				normalized[index] = quoted[1 : len(quoted)-1]
-			case int:
-				normalized[index] = strconv.Itoa(el.(int))
-			case int64:
-				normalized[index] = strconv.FormatInt(el.(int64), 10)
-			case bool:
-				normalized[index] = strconv.FormatBool(el.(bool))
-			case float64:
-				normalized[index] = strconv.FormatFloat(el.(float64), format, -1, 64)
-			case uint64:
-				normalized[index] = strconv.FormatUint(el.(uint64), 10)
-			case nil:
-				normalized[index] = "nil"
-			default:
-				normalized[index] = fmt.Sprintf("%s", el)
-			}
-		}
-		rows[index_1] = &TabulateRow{Elements: normalized}
+	for index, element := range data {
+		rows[index] = &TabulateRow{values: element}
 	}
 	return rows
 }
@@ -48,24 +38,24 @@ func createFromMixed(data [][]interface{}, format byte) []*TabulateRow {
 func createFromInt(data [][]int) []*TabulateRow {
 	rows := make([]*TabulateRow, len(data))
 	for index_1, arr := range data {
-		row := make([]string, len(arr))
+		values := make([]interface{}, len(arr))
 		for index, el := range arr {
-			row[index] = strconv.Itoa(el)
+			values[index] = el
 		}
-		rows[index_1] = &TabulateRow{Elements: row}
+		rows[index_1] = &TabulateRow{values: values}
 	}
 	return rows
 }
 
 // Create normalized array from float64
-func createFromFloat64(data [][]float64, format byte) []*TabulateRow {
+func createFromFloat64(data [][]float64) []*TabulateRow {
 	rows := make([]*TabulateRow, len(data))
 	for index_1, arr := range data {
-		row := make([]string, len(arr))
+		values := make([]interface{}, len(arr))
 		for index, el := range arr {
-			row[index] = strconv.FormatFloat(el, format, -1, 64)
+			values[index] = el
 		}
-		rows[index_1] = &TabulateRow{Elements: row}
+		rows[index_1] = &TabulateRow{values: values}
 	}
 	return rows
 }
@@ -88,11 +78,11 @@ func createFromInt32(data [][]int32) []*TabulateRow {
 func createFromInt64(data [][]int64) []*TabulateRow {
 	rows := make([]*TabulateRow, len(data))
 	for index_1, arr := range data {
-		row := make([]string, len(arr))
+		values := make([]interface{}, len(arr))
 		for index, el := range arr {
-			row[index] = strconv.FormatInt(el, 10)
+			values[index] = el
 		}
-		rows[index_1] = &TabulateRow{Elements: row}
+		rows[index_1] = &TabulateRow{values: values}
 	}
 	return rows
 }
@@ -101,38 +91,729 @@ func createFromInt64(data [][]int64) []*TabulateRow {
 func createFromBool(data [][]bool) []*TabulateRow {
 	rows := make([]*TabulateRow, len(data))
 	for index_1, arr := range data {
-		row := make([]string, len(arr))
+		values := make([]interface{}, len(arr))
 		for index, el := range arr {
-			row[index] = strconv.FormatBool(el)
+			values[index] = el
 		}
-		rows[index_1] = &TabulateRow{Elements: row}
+		rows[index_1] = &TabulateRow{values: values}
 	}
 	return rows
 }
 
 // Create normalized array from a map of mixed elements (interface{})
 // Keys will be used as header
-func createFromMapMixed(data map[string][]interface{}, format byte) (headers []string, tData []*TabulateRow) {
+// Shorter values are padded with nil, so missing cells are told apart from empty ones
+func createFromMapMixed(data map[string][]interface{}) (headers []string, tData []*TabulateRow) {
+	longest := 0
+	for _, value := range data {
+		if len(value) > longest {
+			longest = len(value)
+		}
+	}
 
 	var dataslice [][]interface{}
 	for key, value := range data {
 		headers = append(headers, key)
-		dataslice = append(dataslice, value)
+		padded := make([]interface{}, longest)
+		copy(padded, value)
+		dataslice = append(dataslice, padded)
 	}
-	return headers, createFromMixed(dataslice, format)
+	return headers, createFromMixed(dataslice)
 }
 
 // Create normalized array from Map of strings
 // Keys will be used as header
+// Shorter values are padded with "nil", the marker of missing cells rendered with EmptyVar
 func createFromMapString(data map[string][]string) (headers []string, tData []*TabulateRow) {
+	longest := 0
+	for _, value := range data {
+		if len(value) > longest {
+			longest = len(value)
+		}
+	}
+
 	var dataslice [][]string
 	for key, value := range data {
 		headers = append(headers, key)
-		dataslice = append(dataslice, value)
+		padded := append([]string(nil), value...)
+		for len(padded) < longest {
+			padded = append(padded, "nil")
+		}
+		dataslice = append(dataslice, padded)
 	}
 	return headers, createFromString(dataslice)
 }
 
+// Format the source values of each row into its Elements
+func (t *Tabulate) normalize() {
+	for _, row := range t.Data {
+		if row.values == nil {
+			continue
+		}
+		row.Elements = make([]string, len(row.values))
+		for index, el := range row.values {
+			row.Elements[index] = t.formatValue(el)
+		}
+	}
+}
+
+// Format the cells of the typed columns, on copies of the rows
+func (t *Tabulate) applyColumnTypes(data []*TabulateRow) []*TabulateRow {
+	if len(t.ColumnTypes) < 1 {
+		return data
+	}
+	typed := make([]*TabulateRow, len(data))
+	for i, row := range data {
+		typed[i] = row.clone()
+		for column, kind := range t.ColumnTypes {
+			if column >= len(row.Elements) {
+				break
+			}
+			var value interface{} = row.Elements[column]
+			if column < len(row.values) {
+				value = row.values[column]
+			}
+			if formatted, ok := t.formatTyped(value, kind); ok {
+				typed[i].Elements[column] = formatted
+			}
+		}
+	}
+	return typed
+}
+
+// Apply fn to every cell, only the rows with changed cells are copied
+func mapCells(data []*TabulateRow, fn func(string) string) []*TabulateRow {
+	return mapColumns(data, func(column int, cell string) string {
+		return fn(cell)
+	})
+}
+
+// Apply fn to every cell with the index of its column, only the rows with changed cells are copied
+func mapColumns(data []*TabulateRow, fn func(column int, cell string) string) []*TabulateRow {
+	mapped := make([]*TabulateRow, len(data))
+	for i, row := range data {
+		mapped[i] = row
+		for column, e := range row.Elements {
+			changed := fn(column, e)
+			if changed == e {
+				continue
+			}
+			if mapped[i] == row {
+				mapped[i] = row.clone()
+			}
+			mapped[i].Elements[column] = changed
+		}
+	}
+	return mapped
+}
+
+// Expand the tabs of the cells to spaces, on copies of the rows
+func (t *Tabulate) expandTabs(data []*TabulateRow) []*TabulateRow {
+	if t.TabWidth < 1 {
+		return data
+	}
+	return mapCells(data, t.expandTab)
+}
+
+// Replace every tab with the spaces up to the next tab stop of the line
+func (t *Tabulate) expandTab(s string) string {
+	if !strings.Contains(s, "\t") {
+		return s
+	}
+	var b bytes.Buffer
+	column := 0
+	for _, r := range s {
+		switch r {
+		case '\t':
+			spaces := t.TabWidth - column%t.TabWidth
+			b.WriteString(strings.Repeat(" ", spaces))
+			column += spaces
+		case '\n':
+			b.WriteRune(r)
+			column = 0
+		default:
+			b.WriteRune(r)
+			column += runewidth.RuneWidth(r)
+		}
+	}
+	return b.String()
+}
+
+// Remove the invisible characters of the cells, on copies of the rows
+func (t *Tabulate) stripZeroWidth(data []*TabulateRow) []*TabulateRow {
+	if !t.StripZeroWidth {
+		return data
+	}
+	return mapCells(data, removeZeroWidth)
+}
+
+// Escape the control characters of the cells, on copies of the rows
+func (t *Tabulate) escapeControl(data []*TabulateRow) []*TabulateRow {
+	if !t.EscapeControl {
+		return data
+	}
+	return mapCells(data, escapeControl)
+}
+
+// Remove the trailing zeros of the decimals of the float values, 1.500 becomes 1.5 and 2.000 becomes 2
+// Only the cells of float and currency columns, and the cells holding a float value, are trimmed
+func (t *Tabulate) trimTrailingZeros(data []*TabulateRow) []*TabulateRow {
+	if !t.TrimTrailingZeros {
+		return data
+	}
+	mark := "."
+	if t.DecimalMark != 0 {
+		mark = string(t.DecimalMark)
+	}
+	trimmed := make([]*TabulateRow, len(data))
+	for i, row := range data {
+		trimmed[i] = row
+		for column, e := range row.Elements {
+			if !t.isFloatCell(row, column) {
+				continue
+			}
+			if s := trimZeros(e, mark, t.GroupingMark); s != e {
+				if trimmed[i] == row {
+					trimmed[i] = row.clone()
+				}
+				trimmed[i].Elements[column] = s
+			}
+		}
+	}
+	return trimmed
+}
+
+// Check if a cell is in a float or currency column, or holds a float value
+func (t *Tabulate) isFloatCell(row *TabulateRow, column int) bool {
+	if column < len(t.ColumnTypes) && (t.ColumnTypes[column] == ColumnFloat || t.ColumnTypes[column] == ColumnCurrency) {
+		return true
+	}
+	if column < len(row.values) {
+		switch row.values[column].(type) {
+		case float32, float64:
+			return true
+		}
+	}
+	return false
+}
+
+// Remove the trailing zeros after the decimal mark of a number, other strings are left as they are
+func trimZeros(s string, mark string, grouping rune) string {
+	i := strings.LastIndex(s, mark)
+	if i < 0 {
+		return s
+	}
+	integer, decimals := s[:i], s[i+len(mark):]
+	for j, r := range integer {
+		if !unicode.IsDigit(r) && r != grouping && !(j == 0 && (r == '-' || r == '+')) {
+			return s
+		}
+	}
+	for _, r := range decimals {
+		if !unicode.IsDigit(r) {
+			return s
+		}
+	}
+	// keep a 0 before the decimal mark, so .500 becomes 0.5
+	if len(strings.TrimLeft(integer, "-+")) < 1 {
+		integer += "0"
+	}
+	if decimals = strings.TrimRight(decimals, "0"); len(decimals) < 1 {
+		return integer
+	}
+	return integer + mark + decimals
+}
+
+// Replace control characters with their caret notation, like ^G for the bell
+// Control characters above DEL are written as \x escapes. Newlines and tabs are kept
+func escapeControl(s string) string {
+	var b bytes.Buffer
+	for _, r := range s {
+		switch {
+		case r == '\n' || r == '\t' || !unicode.IsControl(r):
+			b.WriteRune(r)
+		case r < 0x20:
+			b.WriteString("^" + string(r+'@'))
+		case r == 0x7f:
+			b.WriteString("^?")
+		default:
+			b.WriteString(fmt.Sprintf("\\x%02x", r))
+		}
+	}
+	return b.String()
+}
+
+// Remove zero width spaces, non-joiners, byte order marks and soft hyphens
+// Zero width joiners are only kept between the emoji of a sequence
+func removeZeroWidth(s string) string {
+	runes := []rune(s)
+	var b bytes.Buffer
+	for i, r := range runes {
+		switch r {
+		case '\u200b', '\u200c', '\ufeff', '\u00ad':
+			continue
+		case '\u200d':
+			if i == 0 || i == len(runes)-1 || !isEmoji(runes[i-1]) || !isEmoji(runes[i+1]) {
+				continue
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Check if a rune can be part of an emoji sequence
+func isEmoji(r rune) bool {
+	return r >= 0x1f000 || (r >= 0x2600 && r <= 0x27bf) || r == 0xfe0f
+}
+
+// Apply the formatters of the columns to their cells that are not nil, on copies of the rows
+func (t *Tabulate) applyColumnFormatters(data []*TabulateRow) []*TabulateRow {
+	if len(t.ColumnFormatters) < 1 {
+		return data
+	}
+	return mapColumns(data, func(column int, cell string) string {
+		if fn := t.ColumnFormatters[column]; fn != nil && cell != "nil" {
+			return fn(cell)
+		}
+		return cell
+	})
+}
+
+// Add the prefixes and suffixes of the columns to their cells that are not empty, on copies of the rows
+func (t *Tabulate) applyColumnAffixes(data []*TabulateRow) []*TabulateRow {
+	if len(t.ColumnPrefixes) < 1 && len(t.ColumnSuffixes) < 1 {
+		return data
+	}
+	decorated := make([]*TabulateRow, len(data))
+	for i, row := range data {
+		decorated[i] = row.clone()
+		for column, e := range row.Elements {
+			if len(e) < 1 || e == "nil" {
+				continue
+			}
+			decorated[i].Elements[column] = t.ColumnPrefixes[column] + e + t.ColumnSuffixes[column]
+		}
+	}
+	return decorated
+}
+
+// Pad the cells of the columns aligned on a character, so the characters line up
+// The part before the character is padded on the left and the rest on the right
+func (t *Tabulate) alignOnChars(data []*TabulateRow) []*TabulateRow {
+	if len(t.AlignChars) < 1 {
+		return data
+	}
+	// widest parts before and after the character, for each column
+	lefts, rights := make(map[int]int), make(map[int]int)
+	split := func(column int, e string) (string, string, bool) {
+		ch, ok := t.AlignChars[column]
+		if !ok || len(e) < 1 || e == "nil" {
+			return "", "", false
+		}
+		if i := strings.IndexRune(e, ch); i != -1 {
+			return e[:i], e[i:], true
+		}
+		return e, "", true
+	}
+	for _, row := range data {
+		for column, e := range row.Elements {
+			if left, right, ok := split(column, e); ok {
+				if w := t.stringWidth(left); w > lefts[column] {
+					lefts[column] = w
+				}
+				if w := t.stringWidth(right); w > rights[column] {
+					rights[column] = w
+				}
+			}
+		}
+	}
+	aligned := make([]*TabulateRow, len(data))
+	for i, row := range data {
+		aligned[i] = row.clone()
+		for column, e := range row.Elements {
+			if left, right, ok := split(column, e); ok {
+				aligned[i].Elements[column] = strings.Repeat(" ", lefts[column]-t.stringWidth(left)) + left + right + strings.Repeat(" ", rights[column]-t.stringWidth(right))
+			}
+		}
+	}
+	return aligned
+}
+
+// Put the symbol of the currency columns at the left of their cells, and pad the amounts on the left to the widest one
+func (t *Tabulate) alignCurrencies(data []*TabulateRow) []*TabulateRow {
+	if len(t.CurrencySymbols) < 1 {
+		return data
+	}
+	amount := func(column int, e string) (string, bool) {
+		symbol, ok := t.CurrencySymbols[column]
+		if !ok || len(e) < 1 || e == "nil" {
+			return "", false
+		}
+		return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(e), symbol)), true
+	}
+	// widest amount of each column
+	widths := make(map[int]int)
+	for _, row := range data {
+		for column, e := range row.Elements {
+			if a, ok := amount(column, e); ok && t.stringWidth(a) > widths[column] {
+				widths[column] = t.stringWidth(a)
+			}
+		}
+	}
+	aligned := make([]*TabulateRow, len(data))
+	for i, row := range data {
+		aligned[i] = row.clone()
+		for column, e := range row.Elements {
+			if a, ok := amount(column, e); ok {
+				aligned[i].Elements[column] = t.CurrencySymbols[column] + " " + strings.Repeat(" ", widths[column]-t.stringWidth(a)) + a
+			}
+		}
+	}
+	return aligned
+}
+
+// Format a value as the type of its column
+// Strings are parsed first, values that can not be converted are left as they are
+func (t *Tabulate) formatTyped(value interface{}, kind ColumnType) (string, bool) {
+	switch kind {
+	case ColumnInteger, ColumnFloat, ColumnCurrency:
+		f, ok := toFloat(value)
+		if !ok {
+			return "", false
+		}
+		if kind == ColumnInteger {
+			// values with a fraction are not rounded, they are left as they are
+			if f != math.Trunc(f) {
+				return "", false
+			}
+			return t.localizeNumber(strconv.FormatInt(int64(math.Floor(f+0.5)), 10)), true
+		}
+		if kind == ColumnCurrency {
+			return t.localizeNumber(strconv.FormatFloat(f, 'f', 2, 64)), true
+		}
+		return t.formatFloat(f), true
+	case ColumnBool:
+		if s, ok := value.(string); ok {
+			b, err := strconv.ParseBool(strings.TrimSpace(s))
+			if err != nil {
+				return "", false
+			}
+			value = b
+		}
+		if b, ok := value.(bool); ok {
+			return t.formatBool(b), true
+		}
+	case ColumnDate:
+		if s, ok := value.(string); ok {
+			d, err := time.Parse(time.RFC3339, strings.TrimSpace(s))
+			if err != nil {
+				return "", false
+			}
+			value = d
+		}
+		if d, ok := value.(time.Time); ok {
+			return d.Format(t.DateFormat), true
+		}
+	}
+	return "", false
+}
+
+// Convert a number, or a string holding one, to a float
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// Format a single value into its string representation
+func (t *Tabulate) formatValue(el interface{}) string {
+	switch el.(type) {
+	case int32:
+		quoted := strconv.QuoteRuneToASCII(el.(int32))
+		return quoted[1 : len(quoted)-1]
+	case int:
+		return t.localizeNumber(strconv.Itoa(el.(int)))
+	case int64:
+		return t.localizeNumber(strconv.FormatInt(el.(int64), 10))
+	case bool:
+		return t.formatBool(el.(bool))
+	case float64:
+		return t.formatFloat(el.(float64))
+	case uint64:
+		return t.localizeNumber(strconv.FormatUint(el.(uint64), 10))
+	case time.Time:
+		return el.(time.Time).Format(t.DateFormat)
+	case time.Duration:
+		return el.(time.Duration).String()
+	case *Tabulate:
+		return el.(*Tabulate).renderCell()
+	case nil:
+		return "nil"
+	default:
+		return fmt.Sprintf("%s", el)
+	}
+}
+
+// Render a sub-table without lines, to be embedded in a cell of another table
+func (t *Tabulate) renderCell() string {
+	sub := *t
+	sub.TableFormat = TableFormat{
+		HeaderRow: Row{"", " ", ""},
+		DataRow:   Row{"", " ", ""},
+	}
+	var lines []string
+	cols, data := sub.prepare()
+	for _, line := range sub.buildTable(data, cols) {
+		if len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Format a bool, using TrueFormat and FalseFormat if set
+func (t *Tabulate) formatBool(b bool) string {
+	if b && len(t.TrueFormat) > 0 {
+		return t.TrueFormat
+	}
+	if !b && len(t.FalseFormat) > 0 {
+		return t.FalseFormat
+	}
+	return strconv.FormatBool(b)
+}
+
+// Format a float, substituting NaNFormat and InfFormat for non-finite values if set
+func (t *Tabulate) formatFloat(f float64) string {
+	if math.IsNaN(f) && len(t.NaNFormat) > 0 {
+		return t.NaNFormat
+	}
+	if math.IsInf(f, 1) && len(t.InfFormat) > 0 {
+		return t.InfFormat
+	}
+	if math.IsInf(f, -1) && len(t.InfFormat) > 0 {
+		return "-" + t.InfFormat
+	}
+	return t.localizeNumber(strconv.FormatFloat(f, t.FloatFormat, -1, 64))
+}
+
+// Apply the decimal and grouping marks of the locale to a formatted number
+func (t *Tabulate) localizeNumber(number string) string {
+	if t.DecimalMark == 0 && t.GroupingMark == 0 {
+		return number
+	}
+	// split the number into sign, integer digits and the rest (fraction and exponent)
+	start := 0
+	if strings.HasPrefix(number, "-") || strings.HasPrefix(number, "+") {
+		start = 1
+	}
+	end := start
+	for end < len(number) && number[end] >= '0' && number[end] <= '9' {
+		end++
+	}
+	sign, digits, rest := number[:start], number[start:end], number[end:]
+
+	var b bytes.Buffer
+	b.WriteString(sign)
+	for i, digit := range digits {
+		if t.GroupingMark != 0 && i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteRune(t.GroupingMark)
+		}
+		b.WriteRune(digit)
+	}
+	if strings.HasPrefix(rest, ".") && t.DecimalMark != 0 {
+		b.WriteRune(t.DecimalMark)
+		rest = rest[1:]
+	}
+	b.WriteString(rest)
+	return b.String()
+}
+
+// Fit a glyph to the given display width, truncating it or extending it with fill
+func fitGlyph(glyph string, fill string, width int) string {
+	glyphWidth := runewidth.StringWidth(glyph)
+	if glyphWidth > width {
+		return runewidth.Truncate(glyph, width, "")
+	}
+	return glyph + repeatFill(fill, width-glyphWidth)
+}
+
+// Repeat a horizontal line to cover the given display width, padding the remainder with spaces
+// A fill without any width, such as the empty line of formats without borders, is all spaces
+func repeatFill(fill string, width int) string {
+	fillWidth := runewidth.StringWidth(fill)
+	if fillWidth < 1 {
+		fill, fillWidth = " ", 1
+	}
+	b := createBuffer()
+	b.Write(fill, width/fillWidth)
+	b.Write(" ", width%fillWidth)
+	return b.String()
+}
+
+// Group rows into logical rows, a wrapped row is grouped with its continuation rows
+func logicalRows(data []*TabulateRow) [][]*TabulateRow {
+	var rows [][]*TabulateRow
+	var current []*TabulateRow
+	for _, row := range data {
+		current = append(current, row)
+		if !row.Continuous {
+			rows = append(rows, current)
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		rows = append(rows, current)
+	}
+	return rows
+}
+
+// Get the display width of a string, or its byte length if ASCIIFast is set
+func (t *Tabulate) stringWidth(str string) int {
+	if t.ASCIIFast {
+		return len(str)
+	}
+	return runewidth.StringWidth(str)
+}
+
+// ANSI escape sequences, such as the colors of the cells
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;?]*[ -/]*[@-~]")
+
+// Get the display width of a rendered line, without its ANSI escape sequences
+func visibleWidth(line string) int {
+	return runewidth.StringWidth(StripANSI(line))
+}
+
+// Remove the ANSI escape sequences of the colors from a rendered table
+func StripANSI(rendered string) string {
+	return ansiEscape.ReplaceAllString(rendered, "")
+}
+
+// Truncate a string to the given display width
+func (t *Tabulate) truncate(str string, width int) string {
+	if t.ASCIIFast {
+		if len(str) > width {
+			return str[:width]
+		}
+		return str
+	}
+	return runewidth.Truncate(str, width, "")
+}
+
+// Cut a string wider than width with an ellipsis, keeping its end if side is "left",
+// both of its ends if side is "middle", and its start otherwise
+func (t *Tabulate) truncateSide(str string, width int, side string) string {
+	if t.stringWidth(str) <= width || width < 1 {
+		return str
+	}
+	switch side {
+	case "left":
+		return "…" + t.truncateStart(str, width-1)
+	case "middle":
+		tail := (width - 1) / 2
+		return t.truncate(str, width-1-tail) + "…" + t.truncateStart(str, tail)
+	}
+	return t.truncate(str, width-1) + "…"
+}
+
+// Keep the end of a string that fits in the given display width
+func (t *Tabulate) truncateStart(str string, width int) string {
+	runes := []rune(str)
+	i, w := len(runes), 0
+	for i > 0 {
+		rw := t.stringWidth(string(runes[i-1]))
+		if w+rw > width {
+			break
+		}
+		w += rw
+		i--
+	}
+	return string(runes[i:])
+}
+
+// Replace the \r\n and lone \r line endings with \n
+var newlines = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// Get the display width of a cell, the width of its widest line
+func (t *Tabulate) cellWidth(cell string) int {
+	width := 0
+	for _, line := range strings.Split(newlines.Replace(cell), "\n") {
+		if w := t.stringWidth(line); w > width {
+			width = w
+		}
+	}
+	return width
+}
+
+// Percent-encode "%" and every occurrence of delim in a cell
+func escapeSeparator(cell string, delim string) string {
+	if len(delim) < 1 {
+		return cell
+	}
+	var escaped bytes.Buffer
+	for _, c := range []byte(delim) {
+		fmt.Fprintf(&escaped, "%%%02X", c)
+	}
+	cell = strings.Replace(cell, "%", "%25", -1)
+	return strings.Replace(cell, delim, escaped.String(), -1)
+}
+
+// Escape the separator of RenderWithSeparator in the formatted cells, on copies of the rows
+// The "nil" marker of missing cells is kept, they are rendered with the escaped empty string
+func (t *Tabulate) escapeSeparators(data []*TabulateRow) []*TabulateRow {
+	if len(t.escapedSeparator) < 1 {
+		return data
+	}
+	return mapCells(data, func(cell string) string {
+		if cell == "nil" {
+			return cell
+		}
+		return escapeSeparator(cell, t.escapedSeparator)
+	})
+}
+
+// Escape the separator of RenderWithSeparator in the transformed headers
+func (t *Tabulate) escapeHeaders(headers []string) []string {
+	if len(t.escapedSeparator) < 1 {
+		return headers
+	}
+	escaped := make([]string, len(headers))
+	for i, header := range headers {
+		escaped[i] = escapeSeparator(header, t.escapedSeparator)
+	}
+	return escaped
+}
+
+// Parse a cell as a number, written with the decimal and grouping marks of the locale
+func (t *Tabulate) parseNumber(cell string) (float64, bool) {
+	cell = strings.TrimSpace(cell)
+	if t.GroupingMark != 0 {
+		cell = strings.Replace(cell, string(t.GroupingMark), "", -1)
+	}
+	if t.DecimalMark != 0 {
+		cell = strings.Replace(cell, string(t.DecimalMark), ".", -1)
+	}
+	f, err := strconv.ParseFloat(cell, 64)
+	return f, err == nil
+}
+
+// Check if a cell holds a negative number
+func (t *Tabulate) isNegative(cell string) bool {
+	f, ok := t.parseNumber(cell)
+	return ok && f < 0
+}
+
 // Check if element is present in a slice.
 func inSlice(a string, list []string) bool {
 	for _, b := range list {
@@ -142,3 +823,37 @@ func inSlice(a string, list []string) bool {
 	}
 	return false
 }
+
+// Write a number with superscript digits
+func superscript(n int) string {
+	digits := []rune("⁰¹²³⁴⁵⁶⁷⁸⁹")
+	var marker []rune
+	for _, d := range strconv.Itoa(n) {
+		marker = append(marker, digits[d-'0'])
+	}
+	return string(marker)
+}
+
+// Title case a string: the first letter of each word is upper cased, the rest lower cased
+// Words are separated by spaces and punctuation, apostrophes excepted, so "don't" stays one word
+func titleCase(s string) string {
+	var b strings.Builder
+	start := true
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+			if start {
+				b.WriteRune(unicode.ToTitle(r))
+			} else {
+				b.WriteRune(unicode.ToLower(r))
+			}
+			start = false
+		case r == '\'' || r == '’':
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+			start = true
+		}
+	}
+	return b.String()
+}